@@ -0,0 +1,254 @@
+package linux
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"al.essio.dev/pkg/shellescape"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/hashicorp/terraform/communicator/remote"
+	"github.com/spf13/cast"
+)
+
+// attributes holds SELinux context, POSIX ACL and extended attributes of a path.
+// Empty selinuxContext, nil acl or nil xattrs leave the respective attributes untouched.
+type attributes struct {
+	selinuxContext string
+	acl            []string
+	xattrs         map[string]string
+}
+
+func (a attributes) equal(b attributes) bool {
+	return a.selinuxContext == b.selinuxContext &&
+		reflect.DeepEqual(a.acl, b.acl) &&
+		reflect.DeepEqual(a.xattrs, b.xattrs)
+}
+
+// aclBaseEntries are derived from the permission and are not managed as ACL.
+var aclBaseEntries = []string{"user::", "group::", "other::", "mask::"}
+
+// xattrsNamespace is the only namespace of extended attributes that is managed, so that attributes owned by the
+// kernel or security modules, e.g. `security.capability` or `security.ima`, are never removed.
+const xattrsNamespace = "user."
+
+var validateXattrs = validation.MapKeyMatch(regexp.MustCompile(`^`+regexp.QuoteMeta(xattrsNamespace)+`.`),
+	"must be in the `"+xattrsNamespace+"` namespace")
+
+// aclTags maps the abbreviated ACL entry tags accepted by setfacl to the ones printed by getfacl.
+var aclTags = map[string]string{"u": "user", "g": "group", "m": "mask", "o": "other"}
+
+func (l *linux) getAttributes(ctx context.Context, path string) (a attributes, err error) {
+	if a.selinuxContext, err = l.getSelinuxContext(ctx, path); err != nil {
+		return
+	}
+	if a.acl, err = l.getACL(ctx, path); err != nil {
+		return
+	}
+	if a.xattrs, err = l.getXattrs(ctx, path); err != nil {
+		return
+	}
+	return
+}
+
+func (l *linux) setAttributes(ctx context.Context, path string, a attributes) (err error) {
+	if a.selinuxContext != "" {
+		cmd := shellescape.QuoteCommand([]string{"chcon", a.selinuxContext, path})
+		if err = l.exec(ctx, &remote.Cmd{Command: cmd}); err != nil {
+			return fmt.Errorf("while setting selinux context: %w", err)
+		}
+	}
+	if a.acl != nil {
+		if err = l.setACL(ctx, path, a.acl); err != nil {
+			return fmt.Errorf("while setting acl: %w", err)
+		}
+	}
+	if a.xattrs != nil {
+		if err = l.setXattrs(ctx, path, a.xattrs); err != nil {
+			return fmt.Errorf("while setting extended attributes: %w", err)
+		}
+	}
+	return
+}
+
+func (l *linux) getSelinuxContext(ctx context.Context, path string) (s string, err error) {
+	stdout := new(bytes.Buffer)
+	cmd := fmt.Sprintf(`{ %s 2>/dev/null || true ;}`, shellescape.QuoteCommand([]string{"stat", "-c", "%C", path}))
+	if err = l.exec(ctx, &remote.Cmd{Command: cmd, Stdout: stdout}); err != nil {
+		return
+	}
+	s = strings.TrimSpace(stdout.String())
+	if s == "?" {
+		s = "" // selinux is not enabled
+	}
+	return
+}
+
+func (l *linux) getACL(ctx context.Context, path string) (acl []string, err error) {
+	stdout := new(bytes.Buffer)
+	cmd := fmt.Sprintf(`{ ! command -v getfacl >/dev/null || %s ;}`,
+		shellescape.QuoteCommand([]string{"getfacl", "-c", "-E", "-p", path}))
+	if err = l.exec(ctx, &remote.Cmd{Command: cmd, Stdout: stdout}); err != nil {
+		return
+	}
+
+	acl = []string{}
+	for _, line := range strings.Split(stdout.String(), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || isACLBaseEntry(line) {
+			continue
+		}
+		acl = append(acl, normalizeACLEntry(line))
+	}
+	sort.Strings(acl)
+	return
+}
+
+// aclFromSet returns sorted and normalized ACL entries so that they can be compared regardless of order and spelling.
+func aclFromSet(v interface{}) (acl []string) {
+	s, ok := v.(*schema.Set)
+	if !ok {
+		return
+	}
+	for _, e := range cast.ToStringSlice(s.List()) {
+		acl = append(acl, normalizeACLEntry(e))
+	}
+	sort.Strings(acl)
+	return
+}
+
+// aclAsConfigured returns the entries of v, which is the set in the configuration or state, when they are equal to acl
+// after normalization, so that a differently spelled entry isn't reported as drift.
+func aclAsConfigured(v interface{}, acl []string) []string {
+	s, ok := v.(*schema.Set)
+	if !ok || acl == nil || !reflect.DeepEqual(aclFromSet(v), acl) {
+		return acl
+	}
+	return cast.ToStringSlice(s.List())
+}
+
+// normalizeACLEntry returns entry in the form printed by getfacl, e.g. `d:u:alice:rx` becomes
+// `default:user:alice:r-x`.
+func normalizeACLEntry(entry string) string {
+	fields := strings.Split(strings.TrimSpace(entry), ":")
+	for i := range fields {
+		fields[i] = strings.TrimSpace(fields[i])
+	}
+	tag := 0
+	if fields[0] == "d" || fields[0] == "default" {
+		fields[0], tag = "default", 1
+	}
+	if len(fields) < tag+2 {
+		return strings.Join(fields, ":") // malformed, left to setfacl to reject
+	}
+	if t, ok := aclTags[fields[tag]]; ok {
+		fields[tag] = t
+	}
+	if len(fields) == tag+2 && (fields[tag] == "mask" || fields[tag] == "other") {
+		fields = append(fields[:tag+1], "", fields[tag+1]) // the qualifier of mask and other is optional
+	}
+	if perms := fields[len(fields)-1]; strings.Trim(perms, "rwx-") == "" {
+		p := []byte("---")
+		for i, c := range "rwx" {
+			if strings.ContainsRune(perms, c) {
+				p[i] = byte(c)
+			}
+		}
+		fields[len(fields)-1] = string(p)
+	}
+	return strings.Join(fields, ":")
+}
+
+func isACLBaseEntry(entry string) bool {
+	for _, p := range aclBaseEntries {
+		if strings.HasPrefix(entry, p) {
+			return true
+		}
+	}
+	return false
+}
+
+func (l *linux) setACL(ctx context.Context, path string, acl []string) (err error) {
+	cmd := shellescape.QuoteCommand([]string{"setfacl", "-b", "-k", path})
+	if len(acl) > 0 {
+		cmd = fmt.Sprintf(`{ %s && %s ;}`, cmd,
+			shellescape.QuoteCommand([]string{"setfacl", "-m", strings.Join(acl, ","), path}))
+	}
+	return l.exec(ctx, &remote.Cmd{Command: cmd})
+}
+
+func (l *linux) getXattrs(ctx context.Context, path string) (xattrs map[string]string, err error) {
+	stdout := new(bytes.Buffer)
+	cmd := fmt.Sprintf(`{ ! command -v getfattr >/dev/null || %s ;}`,
+		shellescape.QuoteCommand([]string{"getfattr", "-d", "-m", "^" + regexp.QuoteMeta(xattrsNamespace), "-e", "base64",
+			"--absolute-names", path}))
+	if err = l.exec(ctx, &remote.Cmd{Command: cmd, Stdout: stdout}); err != nil {
+		return
+	}
+
+	xattrs = map[string]string{}
+	for _, line := range strings.Split(stdout.String(), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		k, v, _ := strings.Cut(line, "=")
+		if !strings.HasPrefix(k, xattrsNamespace) {
+			continue
+		}
+		if v, err = decodeXattrValue(v); err != nil {
+			return nil, fmt.Errorf("while decoding extended attribute %q: %w", k, err)
+		}
+		xattrs[k] = v
+	}
+	return
+}
+
+func decodeXattrValue(v string) (string, error) {
+	switch {
+	case strings.HasPrefix(v, "0s"):
+		b, err := base64.StdEncoding.DecodeString(v[2:])
+		return string(b), err
+	case strings.HasPrefix(v, `"`):
+		return strings.Trim(v, `"`), nil
+	}
+	return v, nil
+}
+
+// setXattrs sets xattrs and removes the other extended attributes of the managed namespace.
+func (l *linux) setXattrs(ctx context.Context, path string, xattrs map[string]string) (err error) {
+	current, err := l.getXattrs(ctx, path)
+	if err != nil {
+		return
+	}
+
+	keys := make([]string, 0, len(xattrs))
+	for k := range xattrs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	cmds := []string{}
+	for k := range current {
+		if _, ok := xattrs[k]; !ok {
+			cmds = append(cmds, shellescape.QuoteCommand([]string{"setfattr", "-x", k, path}))
+		}
+	}
+	for _, k := range keys {
+		args := []string{"setfattr", "-n", k}
+		if v := xattrs[k]; v != "" {
+			args = append(args, "-v", "0s"+base64.StdEncoding.EncodeToString([]byte(v)))
+		}
+		cmds = append(cmds, shellescape.QuoteCommand(append(args, path)))
+	}
+	if len(cmds) == 0 {
+		return
+	}
+	return l.exec(ctx, &remote.Cmd{Command: fmt.Sprintf(`{ %s ;}`, strings.Join(cmds, " && "))})
+}
@@ -0,0 +1,839 @@
+package linux
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	configFormatINI  = "ini"
+	configFormatJSON = "json"
+	configFormatYAML = "yaml"
+	configFormatTOML = "toml"
+)
+
+var configFormats = []string{configFormatINI, configFormatJSON, configFormatYAML, configFormatTOML}
+
+// configCodec reads and modifies a single key of a structured config content.
+type configCodec interface {
+	get(content string, keys []string) (v interface{}, ok bool, err error)
+	set(content string, keys []string, v interface{}) (string, error)
+	unset(content string, keys []string) (string, error)
+}
+
+func newConfigCodec(format string) (configCodec, error) {
+	switch format {
+	case configFormatINI:
+		return configINI{}, nil
+	case configFormatJSON:
+		return configJSON{}, nil
+	case configFormatYAML:
+		return configYAML{}, nil
+	case configFormatTOML:
+		return configTOML{}, nil
+	}
+	return nil, fmt.Errorf("unsupported config format %q", format)
+}
+
+// splitKeyPath splits a dot separated key path. A literal dot can be escaped with a backslash.
+func splitKeyPath(s string) (keys []string) {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '\\' && i+1 < len(s) && s[i+1] == '.':
+			b.WriteByte('.')
+			i++
+		case s[i] == '.':
+			keys = append(keys, b.String())
+			b.Reset()
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return append(keys, b.String())
+}
+
+// parseConfigValue returns the value decoded as JSON, or the value itself when it is not a valid JSON.
+func parseConfigValue(s string) interface{} {
+	dec := json.NewDecoder(strings.NewReader(s))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil || dec.More() {
+		return s
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return s
+	}
+	return plainConfigValue(v)
+}
+
+// formatConfigValue is the reverse of parseConfigValue.
+func formatConfigValue(v interface{}) (string, error) {
+	if s, ok := v.(string); ok {
+		return s, nil
+	}
+	b, err := json.Marshal(plainConfigValue(v))
+	return string(b), err
+}
+
+func equalConfigValue(a, b string) bool {
+	if a == b {
+		return true
+	}
+	ja, err := json.Marshal(parseConfigValue(a))
+	if err != nil {
+		return false
+	}
+	jb, err := json.Marshal(parseConfigValue(b))
+	if err != nil {
+		return false
+	}
+	return bytes.Equal(ja, jb)
+}
+
+// plainConfigValue converts decoded values into types that can be encoded by every format.
+func plainConfigValue(v interface{}) interface{} {
+	switch t := v.(type) {
+	case json.Number:
+		if i, err := t.Int64(); err == nil {
+			return i
+		}
+		f, _ := t.Float64()
+		return f
+	case *jsonObject:
+		m := make(map[string]interface{}, len(t.keys))
+		for _, k := range t.keys {
+			m[k] = plainConfigValue(t.values[k])
+		}
+		return m
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(t))
+		for k, v := range t {
+			m[k] = plainConfigValue(v)
+		}
+		return m
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(t))
+		for k, v := range t {
+			m[fmt.Sprint(k)] = plainConfigValue(v)
+		}
+		return m
+	case []interface{}:
+		s := make([]interface{}, len(t))
+		for i, v := range t {
+			s[i] = plainConfigValue(v)
+		}
+		return s
+	}
+	return v
+}
+
+// configMap walks a tree of maps, used by formats that don't preserve key order.
+type configMap map[string]interface{}
+
+func (m configMap) get(keys []string) (v interface{}, ok bool) {
+	v = map[string]interface{}(m)
+	for _, k := range keys {
+		mm, isMap := v.(map[string]interface{})
+		if !isMap {
+			return nil, false
+		}
+		if v, ok = mm[k]; !ok {
+			return nil, false
+		}
+	}
+	return v, true
+}
+
+func (m configMap) set(keys []string, v interface{}) error {
+	cur := map[string]interface{}(m)
+	for i, k := range keys[:len(keys)-1] {
+		next, ok := cur[k]
+		if !ok {
+			next = map[string]interface{}{}
+			cur[k] = next
+		}
+		mm, ok := next.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("key '%s' is not a table", strings.Join(keys[:i+1], "."))
+		}
+		cur = mm
+	}
+	cur[keys[len(keys)-1]] = v
+	return nil
+}
+
+func (m configMap) unset(keys []string) {
+	v, ok := m.get(keys[:len(keys)-1])
+	if mm, isMap := v.(map[string]interface{}); ok && isMap {
+		delete(mm, keys[len(keys)-1])
+	}
+}
+
+// configJSON preserves the order of existing keys.
+type configJSON struct{}
+
+type jsonObject struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+func (o *jsonObject) MarshalJSON() ([]byte, error) {
+	buf := bytes.NewBufferString("{")
+	for i, k := range o.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		kb, err := json.Marshal(k)
+		if err != nil {
+			return nil, err
+		}
+		vb, err := json.Marshal(o.values[k])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(kb)
+		buf.WriteByte(':')
+		buf.Write(vb)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+func decodeJSONOrdered(dec *json.Decoder) (v interface{}, err error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return
+	}
+	switch tok {
+	case json.Delim('{'):
+		o := &jsonObject{values: map[string]interface{}{}}
+		for dec.More() {
+			if tok, err = dec.Token(); err != nil {
+				return
+			}
+			k := tok.(string)
+			if v, err = decodeJSONOrdered(dec); err != nil {
+				return
+			}
+			if _, ok := o.values[k]; !ok {
+				o.keys = append(o.keys, k)
+			}
+			o.values[k] = v
+		}
+		_, err = dec.Token()
+		return o, err
+	case json.Delim('['):
+		a := []interface{}{}
+		for dec.More() {
+			if v, err = decodeJSONOrdered(dec); err != nil {
+				return
+			}
+			a = append(a, v)
+		}
+		_, err = dec.Token()
+		return a, err
+	}
+	return tok, nil
+}
+
+func (configJSON) decode(content string) (o *jsonObject, err error) {
+	if strings.TrimSpace(content) == "" {
+		return &jsonObject{values: map[string]interface{}{}}, nil
+	}
+	dec := json.NewDecoder(strings.NewReader(content))
+	dec.UseNumber()
+	v, err := decodeJSONOrdered(dec)
+	if err != nil {
+		return nil, fmt.Errorf("invalid json: %w", err)
+	}
+	o, ok := v.(*jsonObject)
+	if !ok {
+		return nil, errors.New("json root is not an object")
+	}
+	return
+}
+
+func (configJSON) encode(o *jsonObject) (string, error) {
+	b, err := json.MarshalIndent(o, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(b) + "\n", nil
+}
+
+func (c configJSON) walk(o *jsonObject, keys []string, create bool) (parent *jsonObject, err error) {
+	parent = o
+	for i, k := range keys[:len(keys)-1] {
+		next, ok := parent.values[k]
+		if !ok {
+			if !create {
+				return nil, nil
+			}
+			next = &jsonObject{values: map[string]interface{}{}}
+			parent.keys, parent.values[k] = append(parent.keys, k), next
+		}
+		if parent, ok = next.(*jsonObject); !ok {
+			if !create {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("key '%s' is not an object", strings.Join(keys[:i+1], "."))
+		}
+	}
+	return
+}
+
+func (c configJSON) get(content string, keys []string) (v interface{}, ok bool, err error) {
+	o, err := c.decode(content)
+	if err != nil {
+		return
+	}
+	p, err := c.walk(o, keys, false)
+	if err != nil || p == nil {
+		return
+	}
+	v, ok = p.values[keys[len(keys)-1]]
+	return plainConfigValue(v), ok, nil
+}
+
+func (c configJSON) set(content string, keys []string, v interface{}) (s string, err error) {
+	o, err := c.decode(content)
+	if err != nil {
+		return
+	}
+	p, err := c.walk(o, keys, true)
+	if err != nil {
+		return
+	}
+	k := keys[len(keys)-1]
+	if _, ok := p.values[k]; !ok {
+		p.keys = append(p.keys, k)
+	}
+	p.values[k] = v
+	return c.encode(o)
+}
+
+func (c configJSON) unset(content string, keys []string) (s string, err error) {
+	o, err := c.decode(content)
+	if err != nil {
+		return
+	}
+	p, err := c.walk(o, keys, false)
+	if err != nil || p == nil {
+		return content, err
+	}
+	k := keys[len(keys)-1]
+	if _, ok := p.values[k]; !ok {
+		return content, nil
+	}
+	delete(p.values, k)
+	for i := range p.keys {
+		if p.keys[i] == k {
+			p.keys = append(p.keys[:i], p.keys[i+1:]...)
+			break
+		}
+	}
+	return c.encode(o)
+}
+
+// configYAML preserves the order of existing keys and comments.
+type configYAML struct{}
+
+func (configYAML) decode(content string) (doc *yaml.Node, err error) {
+	doc = &yaml.Node{}
+	if err = yaml.Unmarshal([]byte(content), doc); err != nil {
+		return nil, fmt.Errorf("invalid yaml: %w", err)
+	}
+	if len(doc.Content) == 0 {
+		doc = &yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
+	}
+	if doc.Content[0].Kind != yaml.MappingNode {
+		return nil, errors.New("yaml root is not a mapping")
+	}
+	return
+}
+
+func (configYAML) encode(doc *yaml.Node) (string, error) {
+	buf := new(bytes.Buffer)
+	enc := yaml.NewEncoder(buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(doc); err != nil {
+		return "", err
+	}
+	if err := enc.Close(); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// lookup returns the index of the value node of key k in the mapping node n, or -1.
+func (configYAML) lookup(n *yaml.Node, k string) int {
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == k {
+			return i + 1
+		}
+	}
+	return -1
+}
+
+func (c configYAML) walk(doc *yaml.Node, keys []string, create bool) (parent *yaml.Node, err error) {
+	parent = doc.Content[0]
+	for i, k := range keys[:len(keys)-1] {
+		idx := c.lookup(parent, k)
+		if idx < 0 {
+			if !create {
+				return nil, nil
+			}
+			parent.Content = append(parent.Content,
+				&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: k},
+				&yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"})
+			idx = len(parent.Content) - 1
+		}
+		if parent = parent.Content[idx]; parent.Kind != yaml.MappingNode {
+			if !create {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("key '%s' is not a mapping", strings.Join(keys[:i+1], "."))
+		}
+	}
+	return
+}
+
+func (c configYAML) get(content string, keys []string) (v interface{}, ok bool, err error) {
+	doc, err := c.decode(content)
+	if err != nil {
+		return
+	}
+	p, err := c.walk(doc, keys, false)
+	if err != nil || p == nil {
+		return
+	}
+	idx := c.lookup(p, keys[len(keys)-1])
+	if idx < 0 {
+		return
+	}
+	if err = p.Content[idx].Decode(&v); err != nil {
+		return
+	}
+	return plainConfigValue(v), true, nil
+}
+
+func (c configYAML) set(content string, keys []string, v interface{}) (s string, err error) {
+	doc, err := c.decode(content)
+	if err != nil {
+		return
+	}
+	p, err := c.walk(doc, keys, true)
+	if err != nil {
+		return
+	}
+	n := &yaml.Node{}
+	if err = n.Encode(v); err != nil {
+		return
+	}
+	k := keys[len(keys)-1]
+	if idx := c.lookup(p, k); idx >= 0 {
+		n.HeadComment, n.LineComment, n.FootComment = p.Content[idx].HeadComment, p.Content[idx].LineComment, p.Content[idx].FootComment
+		p.Content[idx] = n
+	} else {
+		p.Content = append(p.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: k}, n)
+	}
+	return c.encode(doc)
+}
+
+func (c configYAML) unset(content string, keys []string) (s string, err error) {
+	doc, err := c.decode(content)
+	if err != nil {
+		return
+	}
+	p, err := c.walk(doc, keys, false)
+	if err != nil || p == nil {
+		return content, err
+	}
+	idx := c.lookup(p, keys[len(keys)-1])
+	if idx < 0 {
+		return content, nil
+	}
+	p.Content = append(p.Content[:idx-1], p.Content[idx+1:]...)
+	return c.encode(doc)
+}
+
+// configTOML edits lines in place like configINI, so that comments and the order of existing keys are preserved.
+// A key is looked up under the deepest table header of its path, also matching dotted keys. Every edit is verified
+// by decoding the result, and refused when it would change anything other than the key.
+type configTOML struct{}
+
+func (configTOML) decode(content string) (m configMap, err error) {
+	m = configMap{}
+	if err = toml.Unmarshal([]byte(content), (*map[string]interface{})(&m)); err != nil {
+		return nil, fmt.Errorf("invalid toml: %w", err)
+	}
+	return
+}
+
+func (c configTOML) get(content string, keys []string) (v interface{}, ok bool, err error) {
+	m, err := c.decode(content)
+	if err != nil {
+		return
+	}
+	v, ok = m.get(keys)
+	return plainConfigValue(v), ok, nil
+}
+
+// keyPath parses the possibly dotted and quoted key at the start of s, returning the text after it.
+func (configTOML) keyPath(s string) (keys []string, rest string, ok bool) {
+	rest = strings.TrimLeft(s, " \t")
+	for {
+		var k string
+		switch {
+		case strings.HasPrefix(rest, `"`):
+			i := 1
+			for ; i < len(rest) && rest[i] != '"'; i++ {
+				if rest[i] == '\\' {
+					i++
+				}
+			}
+			if i >= len(rest) {
+				return nil, s, false
+			}
+			if err := json.Unmarshal([]byte(rest[:i+1]), &k); err != nil {
+				return nil, s, false
+			}
+			rest = rest[i+1:]
+		case strings.HasPrefix(rest, "'"):
+			i := strings.IndexByte(rest[1:], '\'')
+			if i < 0 {
+				return nil, s, false
+			}
+			k, rest = rest[1:i+1], rest[i+2:]
+		default:
+			i := strings.IndexFunc(rest, func(r rune) bool {
+				return !(r >= 'A' && r <= 'Z' || r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '_' || r == '-')
+			})
+			if i < 0 {
+				i = len(rest)
+			}
+			if i == 0 {
+				return nil, s, false
+			}
+			k, rest = rest[:i], rest[i:]
+		}
+		keys = append(keys, k)
+		rest = strings.TrimLeft(rest, " \t")
+		if !strings.HasPrefix(rest, ".") {
+			return keys, rest, true
+		}
+		rest = strings.TrimLeft(rest[1:], " \t")
+	}
+}
+
+// header parses a table header. Array of tables are headers that never match any key path.
+func (c configTOML) header(line string) (keys []string, ok bool) {
+	t := strings.TrimSpace(line)
+	if !strings.HasPrefix(t, "[") {
+		return nil, false
+	}
+	if strings.HasPrefix(t, "[[") {
+		return nil, true
+	}
+	keys, rest, ok := c.keyPath(t[1:])
+	if !ok || !strings.HasPrefix(rest, "]") {
+		return nil, false
+	}
+	if rest = strings.TrimSpace(rest[1:]); rest != "" && !strings.HasPrefix(rest, "#") {
+		return nil, false
+	}
+	return keys, true
+}
+
+// section returns the range of lines belonging to a table, excluding its header.
+// start is -1 if the table has no header. The root table always exists.
+func (c configTOML) section(lines []string, table []string) (start, end int) {
+	start, end = -1, len(lines)
+	if len(table) == 0 {
+		start = 0
+	}
+	for i, l := range lines {
+		keys, ok := c.header(l)
+		if !ok {
+			continue
+		}
+		if start >= 0 {
+			return start, i
+		}
+		if slices.Equal(keys, table) {
+			start = i + 1
+		}
+	}
+	return
+}
+
+// extent returns the end of the key/value pair starting at line i, which may span multiple lines.
+func (configTOML) extent(lines []string, i int) int {
+	for j := i; j < len(lines); j++ {
+		if toml.Unmarshal([]byte(strings.Join(lines[i:j+1], "\n")), &map[string]interface{}{}) == nil {
+			return j + 1
+		}
+	}
+	return i + 1
+}
+
+// lookup returns the range of lines of the key/value pair of keys, and the key as written in the content.
+func (c configTOML) lookup(lines []string, keys []string) (start, end int, key string, ok bool) {
+	for j := len(keys) - 1; j >= 0; j-- {
+		s, e := c.section(lines, keys[:j])
+		if s < 0 {
+			continue
+		}
+		for i := s; i < e; i++ {
+			t := strings.TrimSpace(lines[i])
+			if t == "" || strings.HasPrefix(t, "#") {
+				continue
+			}
+			kp, rest, isKey := c.keyPath(t)
+			if isKey && strings.HasPrefix(rest, "=") && slices.Equal(kp, keys[j:]) {
+				return i, c.extent(lines, i), strings.TrimSpace(t[:len(t)-len(rest)]), true
+			}
+		}
+	}
+	return
+}
+
+func (configTOML) formatKey(keys []string) string {
+	f := make([]string, len(keys))
+	for i, k := range keys {
+		if k != "" && strings.Trim(k, "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789_-") == "" {
+			f[i] = k
+			continue
+		}
+		b, _ := json.Marshal(k)
+		f[i] = string(b)
+	}
+	return strings.Join(f, ".")
+}
+
+func (configTOML) formatValue(v interface{}) (string, error) {
+	if s, ok := v.(string); ok {
+		b, err := json.Marshal(s) // a basic string, json escapes are valid in toml
+		return string(b), err
+	}
+	buf := new(bytes.Buffer)
+	enc := toml.NewEncoder(buf).SetTablesInline(true).SetArraysMultiline(false)
+	if err := enc.Encode(map[string]interface{}{"v": v}); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(strings.TrimPrefix(buf.String(), "v = "), "\n"), nil
+}
+
+// comment returns the comment trailing the single line key/value pair, including the leading whitespaces.
+func (configTOML) comment(line string) string {
+	for i := strings.IndexByte(line, '#'); i >= 0; {
+		if toml.Unmarshal([]byte(line[:i]), &map[string]interface{}{}) == nil {
+			return line[len(strings.TrimRight(line[:i], " \t")):]
+		}
+		j := strings.IndexByte(line[i+1:], '#')
+		if j < 0 {
+			break
+		}
+		i += j + 1
+	}
+	return ""
+}
+
+// verify ensures that content decodes to expected, ignoring empty tables left by unset.
+func (c configTOML) verify(content string, expected configMap) (string, error) {
+	m, err := c.decode(content)
+	if err == nil && reflect.DeepEqual(c.prune(map[string]interface{}(m)), c.prune(map[string]interface{}(expected))) {
+		return content, nil
+	}
+	return "", errors.New("the toml content is too complex to be edited in place")
+}
+
+func (c configTOML) prune(v interface{}) interface{} {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return plainConfigValue(v)
+	}
+	p := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		if mm, isMap := c.prune(v).(map[string]interface{}); !isMap {
+			p[k] = c.prune(v)
+		} else if len(mm) > 0 {
+			p[k] = mm
+		}
+	}
+	return p
+}
+
+func (c configTOML) set(content string, keys []string, v interface{}) (s string, err error) {
+	expected, err := c.decode(content)
+	if err != nil {
+		return
+	}
+	if err = expected.set(keys, v); err != nil {
+		return
+	}
+	value, err := c.formatValue(v)
+	if err != nil {
+		return
+	}
+	lines, _ := splitLines(content)
+
+	if start, end, key, ok := c.lookup(lines, keys); ok {
+		indent := lines[start][:len(lines[start])-len(strings.TrimLeft(lines[start], " \t"))]
+		entry := indent + key + " = " + value
+		if end == start+1 {
+			entry += c.comment(lines[start])
+		}
+		lines = append(lines[:start], append([]string{entry}, lines[end:]...)...)
+		return c.verify(joinLines(lines, true), expected)
+	}
+
+	if start, _ := c.section(lines, keys[:len(keys)-1]); start < 0 {
+		table := lines
+		if len(table) > 0 && strings.TrimSpace(table[len(table)-1]) != "" {
+			table = append(table, "")
+		}
+		table = append(table, "["+c.formatKey(keys[:len(keys)-1])+"]", c.formatKey(keys[len(keys)-1:])+" = "+value)
+		if s, err = c.verify(joinLines(table, true), expected); err == nil {
+			return
+		}
+	}
+	for j := len(keys) - 1; j >= 0; j-- { // otherwise insert as dotted key into the deepest existing table
+		start, end := c.section(lines, keys[:j])
+		if start < 0 {
+			continue
+		}
+		for end > start && strings.TrimSpace(lines[end-1]) == "" {
+			end-- // keep blank lines separating tables
+		}
+		entry := c.formatKey(keys[j:]) + " = " + value
+		lines = append(lines[:end], append([]string{entry}, lines[end:]...)...)
+		break
+	}
+	return c.verify(joinLines(lines, true), expected)
+}
+
+func (c configTOML) unset(content string, keys []string) (s string, err error) {
+	expected, err := c.decode(content)
+	if err != nil {
+		return
+	}
+	if _, ok := expected.get(keys); !ok {
+		return content, nil
+	}
+	expected.unset(keys)
+
+	lines, eol := splitLines(content)
+	start, end, _, ok := c.lookup(lines, keys)
+	if !ok {
+		return c.verify(content, expected) // e.g. defined in an inline table
+	}
+	return c.verify(joinLines(append(lines[:start], lines[end:]...), eol), expected)
+}
+
+// configINI edits lines in place, so that comments and formatting are preserved.
+// The last element of the key path is the key and the rest is the section name.
+// A key path without section refers to keys before the first section.
+type configINI struct{}
+
+func (configINI) split(keys []string) (section, key string) {
+	return strings.Join(keys[:len(keys)-1], "."), keys[len(keys)-1]
+}
+
+// section returns the range of lines belonging to a section, excluding its header.
+// start is -1 if the section doesn't exist.
+func (configINI) section(lines []string, name string) (start, end int) {
+	start, end = -1, len(lines)
+	if name == "" {
+		start = 0
+	}
+	for i, l := range lines {
+		t := strings.TrimSpace(l)
+		if !strings.HasPrefix(t, "[") || !strings.HasSuffix(t, "]") {
+			continue
+		}
+		if start >= 0 {
+			return start, i
+		}
+		if strings.TrimSpace(t[1:len(t)-1]) == name {
+			start = i + 1
+		}
+	}
+	return
+}
+
+func (configINI) parse(line string) (key, value string, ok bool) {
+	t := strings.TrimSpace(line)
+	if t == "" || strings.HasPrefix(t, "#") || strings.HasPrefix(t, ";") {
+		return
+	}
+	key, value, ok = strings.Cut(t, "=")
+	return strings.TrimSpace(key), strings.TrimSpace(value), ok
+}
+
+func (c configINI) lookup(lines []string, keys []string) (idx int, value string) {
+	section, key := c.split(keys)
+	start, end := c.section(lines, section)
+	if start < 0 {
+		return -1, ""
+	}
+	for i := start; i < end; i++ {
+		if k, v, ok := c.parse(lines[i]); ok && k == key {
+			return i, v
+		}
+	}
+	return -1, ""
+}
+
+func (c configINI) get(content string, keys []string) (v interface{}, ok bool, err error) {
+	lines, _ := splitLines(content)
+	idx, value := c.lookup(lines, keys)
+	return value, idx >= 0, nil
+}
+
+func (c configINI) set(content string, keys []string, v interface{}) (s string, err error) {
+	value, err := formatConfigValue(v)
+	if err != nil {
+		return
+	}
+	section, key := c.split(keys)
+	entry := key + " = " + value
+	lines, _ := splitLines(content)
+
+	if idx, _ := c.lookup(lines, keys); idx >= 0 {
+		lines[idx] = entry
+		return joinLines(lines, true), nil
+	}
+
+	start, end := c.section(lines, section)
+	if start < 0 {
+		if len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) != "" {
+			lines = append(lines, "")
+		}
+		return joinLines(append(lines, "["+section+"]", entry), true), nil
+	}
+	for end > start && strings.TrimSpace(lines[end-1]) == "" {
+		end-- // keep blank lines separating sections
+	}
+	lines = append(lines[:end], append([]string{entry}, lines[end:]...)...)
+	return joinLines(lines, true), nil
+}
+
+func (c configINI) unset(content string, keys []string) (s string, err error) {
+	lines, eol := splitLines(content)
+	idx, _ := c.lookup(lines, keys)
+	if idx < 0 {
+		return content, nil
+	}
+	return joinLines(append(lines[:idx], lines[idx+1:]...), eol), nil
+}
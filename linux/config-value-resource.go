@@ -0,0 +1,157 @@
+package linux
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/spf13/cast"
+)
+
+const (
+	attrConfigValueProviderOverride = "provider_override"
+	attrConfigValuePath             = "path"
+	attrConfigValueFormat           = "format"
+	attrConfigValueKeyPath          = "key_path"
+	attrConfigValueValue            = "value"
+	attrConfigValueCreate           = "create"
+)
+
+var schemaConfigValueResource = map[string]*schema.Schema{
+	attrConfigValueProviderOverride: {
+		Type:     schema.TypeList,
+		Optional: true,
+		MaxItems: 1,
+		Elem: &schema.Resource{
+			Schema: subSchemaProviderOverride,
+		},
+	},
+
+	attrConfigValuePath: {
+		Type:     schema.TypeString,
+		Required: true,
+		ForceNew: true,
+	},
+	attrConfigValueFormat: {
+		Type:         schema.TypeString,
+		Required:     true,
+		ForceNew:     true,
+		ValidateFunc: validation.StringInSlice(configFormats, false),
+	},
+	attrConfigValueKeyPath: {
+		Type:         schema.TypeString,
+		Required:     true,
+		ForceNew:     true,
+		ValidateFunc: validation.StringIsNotEmpty,
+		Description:  "Dot separated path of the key. Use `\\.` for a literal dot",
+	},
+	attrConfigValueValue: {
+		Type:     schema.TypeString,
+		Required: true,
+		DiffSuppressFunc: func(k, old, new string, rd *schema.ResourceData) bool {
+			if cast.ToString(rd.Get(attrConfigValueFormat)) == configFormatINI {
+				return old == new
+			}
+			return equalConfigValue(old, new)
+		},
+		Description: "Value of the key. Except for `ini`, a valid JSON is written as the decoded value, e.g. `jsonencode(...)`",
+	},
+	attrConfigValueCreate: {
+		Type:        schema.TypeBool,
+		Optional:    true,
+		Default:     false,
+		Description: "If true, the file will be created when it doesn't exist",
+	},
+}
+
+type handlerConfigValueResource struct{}
+
+func (handlerConfigValueResource) newConfigValue(rd *schema.ResourceData) (cv *configValue) {
+	if rd == nil {
+		return
+	}
+	return &configValue{
+		path:    cast.ToString(rd.Get(attrConfigValuePath)),
+		format:  cast.ToString(rd.Get(attrConfigValueFormat)),
+		keyPath: cast.ToString(rd.Get(attrConfigValueKeyPath)),
+		value:   cast.ToString(rd.Get(attrConfigValueValue)),
+		create:  cast.ToBool(rd.Get(attrConfigValueCreate)),
+	}
+}
+
+func (h handlerConfigValueResource) Read(ctx context.Context, rd *schema.ResourceData, meta interface{}) (d diag.Diagnostics) {
+	l, err := getLinux(meta.(*linuxPool), rd)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	v, err := l.readConfigValue(ctx, h.newConfigValue(rd))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if v == nil {
+		rd.SetId("") // the key was removed, will be set again
+		return
+	}
+	if err = rd.Set(attrConfigValueValue, *v); err != nil {
+		return diag.FromErr(err)
+	}
+	return
+}
+
+func (h handlerConfigValueResource) Create(ctx context.Context, rd *schema.ResourceData, meta interface{}) (d diag.Diagnostics) {
+	l, err := getLinux(meta.(*linuxPool), rd)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if err := l.setConfigValue(ctx, h.newConfigValue(rd)); err != nil {
+		return diag.FromErr(err)
+	}
+
+	id, err := uuid.NewRandom()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	rd.SetId(id.String())
+	return h.Read(ctx, rd, meta)
+}
+
+func (h handlerConfigValueResource) Update(ctx context.Context, rd *schema.ResourceData, meta interface{}) (d diag.Diagnostics) {
+	l, err := getLinux(meta.(*linuxPool), rd)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if rd.HasChange(attrConfigValueValue) {
+		if err := l.setConfigValue(ctx, h.newConfigValue(rd)); err != nil {
+			o, _ := rd.GetChange(attrConfigValueValue)
+			_ = rd.Set(attrConfigValueValue, o) // WARN: see https://github.com/hashicorp/terraform-plugin-sdk/issues/476
+			return diag.FromErr(err)
+		}
+	}
+	return h.Read(ctx, rd, meta)
+}
+
+func (h handlerConfigValueResource) Delete(ctx context.Context, rd *schema.ResourceData, meta interface{}) (d diag.Diagnostics) {
+	l, err := getLinux(meta.(*linuxPool), rd)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := l.unsetConfigValue(ctx, h.newConfigValue(rd)); err != nil {
+		return diag.FromErr(err)
+	}
+	return
+}
+
+func configValueResource() *schema.Resource {
+	var hcvr handlerConfigValueResource
+	return &schema.Resource{
+		Schema:        schemaConfigValueResource,
+		CreateContext: hcvr.Create,
+		ReadContext:   hcvr.Read,
+		UpdateContext: hcvr.Update,
+		DeleteContext: hcvr.Delete,
+	}
+}
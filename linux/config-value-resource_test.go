@@ -0,0 +1,209 @@
+package linux
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccLinuxConfigValueBasic(t *testing.T) {
+	path := fmt.Sprintf(`"/tmp/linux/%s.json"`, acctest.RandString(16))
+	conf1 := tfConf{
+		Provider: testAccProvider,
+		ConfigValue: tfmap{
+			attrConfigValuePath:    path,
+			attrConfigValueFormat:  `"json"`,
+			attrConfigValueKeyPath: `"log-opts.max-size"`,
+			attrConfigValueValue:   `"10m"`,
+		},
+		Extra: tfmap{"expected": `"10m"`},
+	}
+	conf2 := conf1.Copy(func(tc *tfConf) {
+		tc.ConfigValue.With(attrConfigValueValue, `jsonencode(["a", "b"])`)
+		tc.Extra.With("expected", `"[\"a\",\"b\"]"`)
+	})
+	conf3 := conf1.Copy(func(tc *tfConf) {
+		tc.ConfigValue.With(attrConfigValueKeyPath, `"debug"`)
+		tc.ConfigValue.With(attrConfigValueValue, `"true"`)
+		tc.Extra.With("expected", `"true"`)
+	})
+
+	resource.Test(t, resource.TestCase{
+		ExternalProviders: map[string]resource.ExternalProvider{"null": {}},
+		PreCheck:          testAccPreCheckConnection(t),
+		Providers:         testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccLinuxConfigValueBasicConfig(t, conf1),
+			},
+			{
+				Config: testAccLinuxConfigValueBasicConfig(t, conf2),
+			},
+			{
+				Config: testAccLinuxConfigValueBasicConfig(t, conf3),
+			},
+		},
+	})
+}
+
+func testAccLinuxConfigValueBasicConfig(t *testing.T, conf tfConf) (s string) {
+	tf := heredoc.Doc(`
+		provider "linux" {
+			alias = "test"
+		    {{- .Provider.Serialize | nindent 4 }}
+		}
+
+		resource "null_resource" "file" {
+		    triggers = {
+		        path = {{ .ConfigValue.path }}
+		    }
+		    connection {
+		        type = "ssh"
+		        {{- .Provider.Serialize | nindent 8 }}
+		    }
+		    provisioner "remote-exec" {
+		        inline = [
+		            <<-EOF
+		                mkdir -p "$(dirname '${self.triggers["path"]}')"
+		                printf '{"data-root": "/var/lib/docker"}\n' > '${self.triggers["path"]}'
+		            EOF
+		        ]
+		    }
+		    provisioner "remote-exec" {
+		        when = destroy
+		        inline = [
+		            <<-EOF
+		                grep -q '"data-root": "/var/lib/docker"' '${self.triggers["path"]}' || exit 100
+		                rm -f '${self.triggers["path"]}'
+		            EOF
+		        ]
+		    }
+		}
+
+		resource "linux_config_value" "value" {
+			provider = "linux.test"
+		    depends_on = [ null_resource.file ]
+
+		    {{- .ConfigValue.Serialize | nindent 4 }}
+		}
+
+		resource "null_resource" "create_validator" {
+		    triggers = {
+		        id = linux_config_value.value.id
+		        value = linux_config_value.value.value
+		    }
+		    connection {
+		        type = "ssh"
+		        {{- .Provider.Serialize | nindent 8 }}
+		    }
+		    provisioner "remote-exec" {
+		        inline = [
+		            <<-EOF
+		                [ '${self.triggers["value"]}' == {{ .Extra.expected }} ] || exit 101
+		                grep -q '"data-root": "/var/lib/docker"' {{ .ConfigValue.path }} || exit 102
+		            EOF
+		        ]
+		    }
+		}
+	`)
+
+	s, err := conf.compile(tf)
+	t.Log(s)
+	require.NoError(t, err, "compile template failed")
+	return
+}
+
+func TestAccLinuxConfigValueTOML(t *testing.T) {
+	conf1 := tfConf{
+		Provider: testAccProvider,
+		ConfigValue: tfmap{
+			attrConfigValuePath:    fmt.Sprintf(`"/tmp/linux/%s.toml"`, acctest.RandString(16)),
+			attrConfigValueFormat:  `"toml"`,
+			attrConfigValueKeyPath: `"server.port"`,
+			attrConfigValueValue:   `"8080"`,
+		},
+	}
+	conf2 := conf1.Copy(func(tc *tfConf) {
+		tc.ConfigValue.With(attrConfigValueKeyPath, `"server.name"`)
+		tc.ConfigValue.With(attrConfigValueValue, `"web"`)
+	})
+
+	resource.Test(t, resource.TestCase{
+		ExternalProviders: map[string]resource.ExternalProvider{"null": {}},
+		PreCheck:          testAccPreCheckConnection(t),
+		Providers:         testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccLinuxConfigValueTOMLConfig(t, conf1),
+			},
+			{
+				Config: testAccLinuxConfigValueTOMLConfig(t, conf2),
+			},
+		},
+	})
+}
+
+func testAccLinuxConfigValueTOMLConfig(t *testing.T, conf tfConf) (s string) {
+	tf := heredoc.Doc(`
+		provider "linux" {
+			alias = "test"
+		    {{- .Provider.Serialize | nindent 4 }}
+		}
+
+		resource "null_resource" "file" {
+		    triggers = {
+		        path = {{ .ConfigValue.path }}
+		    }
+		    connection {
+		        type = "ssh"
+		        {{- .Provider.Serialize | nindent 8 }}
+		    }
+		    provisioner "remote-exec" {
+		        inline = [
+		            <<-EOF
+		                mkdir -p "$(dirname '${self.triggers["path"]}')"
+		                printf '# managed elsewhere\nzeta = 1 # keep\n\n[server]\nport = 80\n' > '${self.triggers["path"]}'
+		            EOF
+		        ]
+		    }
+		    provisioner "remote-exec" {
+		        when = destroy
+		        inline = [ "rm -f '${self.triggers["path"]}'" ]
+		    }
+		}
+
+		resource "linux_config_value" "value" {
+			provider = "linux.test"
+		    depends_on = [ null_resource.file ]
+
+		    {{- .ConfigValue.Serialize | nindent 4 }}
+		}
+
+		resource "null_resource" "create_validator" {
+		    triggers = {
+		        id = linux_config_value.value.id
+		        key_path = linux_config_value.value.key_path
+		    }
+		    connection {
+		        type = "ssh"
+		        {{- .Provider.Serialize | nindent 8 }}
+		    }
+		    provisioner "remote-exec" {
+		        inline = [
+		            <<-EOF
+		                [ "$(head -n 2 {{ .ConfigValue.path }})" == "$(printf '# managed elsewhere\nzeta = 1 # keep')" ] || exit 101
+		                grep -qx '\[server\]' {{ .ConfigValue.path }} || exit 102
+		            EOF
+		        ]
+		    }
+		}
+	`)
+	s, err := conf.compile(tf)
+	t.Log(s)
+	require.NoError(t, err, "compile template failed")
+	return
+}
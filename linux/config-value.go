@@ -0,0 +1,103 @@
+package linux
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+type configValue struct {
+	path    string
+	format  string
+	keyPath string
+	value   string
+	create  bool
+}
+
+func (cv *configValue) codec() (c configCodec, keys []string, err error) {
+	if c, err = newConfigCodec(cv.format); err != nil {
+		return
+	}
+	return c, splitKeyPath(cv.keyPath), nil
+}
+
+// readConfigValue returns the current value of the key, or nil when either the file or the key doesn't exist.
+func (l *linux) readConfigValue(ctx context.Context, cv *configValue) (value *string, err error) {
+	if cv == nil {
+		return nil, errNil
+	}
+	c, keys, err := cv.codec()
+	if err != nil {
+		return
+	}
+	content, exist, err := l.readFileLineContent(ctx, cv.path)
+	if err != nil || !exist {
+		return
+	}
+	v, ok, err := c.get(content, keys)
+	if err != nil {
+		return nil, fmt.Errorf("while reading '%s': %w", cv.path, err)
+	}
+	if !ok {
+		return
+	}
+	s := ""
+	if cv.format == configFormatINI {
+		s = fmt.Sprint(v)
+	} else if s, err = formatConfigValue(v); err != nil {
+		return
+	}
+	return &s, nil
+}
+
+func (l *linux) setConfigValue(ctx context.Context, cv *configValue) (err error) {
+	if cv == nil {
+		return errNil
+	}
+	c, keys, err := cv.codec()
+	if err != nil {
+		return
+	}
+	content, exist, err := l.readFileLineContent(ctx, cv.path)
+	if err != nil {
+		return
+	}
+	if !exist && !cv.create {
+		return fmt.Errorf("path '%s' doesn't exist", cv.path)
+	}
+
+	var v interface{} = cv.value
+	if cv.format != configFormatINI {
+		v = parseConfigValue(cv.value)
+	}
+	s, err := c.set(content, keys, v)
+	if err != nil {
+		return fmt.Errorf("while setting '%s' in '%s': %w", cv.keyPath, cv.path, err)
+	}
+	if exist && s == content {
+		return
+	}
+	return l.replace(ctx, cv.path, strings.NewReader(s))
+}
+
+func (l *linux) unsetConfigValue(ctx context.Context, cv *configValue) (err error) {
+	if cv == nil {
+		return
+	}
+	c, keys, err := cv.codec()
+	if err != nil {
+		return
+	}
+	content, exist, err := l.readFileLineContent(ctx, cv.path)
+	if err != nil || !exist {
+		return
+	}
+	s, err := c.unset(content, keys)
+	if err != nil {
+		return fmt.Errorf("while removing '%s' from '%s': %w", cv.keyPath, cv.path, err)
+	}
+	if s == content {
+		return
+	}
+	return l.replace(ctx, cv.path, strings.NewReader(s))
+}
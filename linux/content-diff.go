@@ -0,0 +1,69 @@
+package linux
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// unifiedDiff returns the unified diff between from and to, labelled with fromFile and toFile.
+func unifiedDiff(fromFile, toFile, from, to string) string {
+	s, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        diffLines(from),
+		B:        diffLines(to),
+		FromFile: fromFile,
+		ToFile:   toFile,
+		Context:  3,
+	})
+	if err != nil {
+		return err.Error()
+	}
+	return s
+}
+
+// diffLines splits s into newline terminated lines, unlike difflib.SplitLines which yields a line for empty input.
+func diffLines(s string) (lines []string) {
+	if s == "" {
+		return
+	}
+	lines = strings.SplitAfter(s, "\n")
+	if last := len(lines) - 1; lines[last] == "" {
+		lines = lines[:last]
+	} else {
+		lines[last] += "\n"
+	}
+	return
+}
+
+// redactDiff replaces the text of changed and context lines of diff that match any of patterns, keeping their
+// prefix so that the shape of the diff is still visible.
+func redactDiff(diff string, patterns []*regexp.Regexp) string {
+	if len(patterns) == 0 {
+		return diff
+	}
+	lines := strings.SplitAfter(diff, "\n")
+	for i, line := range lines {
+		if i < 2 || line == "" || strings.HasPrefix(line, "@@") {
+			continue // file headers and hunk ranges
+		}
+		for _, p := range patterns {
+			if p.MatchString(strings.TrimSuffix(line[1:], "\n")) {
+				lines[i] = line[:1] + "(redacted)\n"
+				break
+			}
+		}
+	}
+	return strings.Join(lines, "")
+}
+
+func compileRedactPatterns(patterns []string) (res []*regexp.Regexp, err error) {
+	for _, p := range patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, re)
+	}
+	return
+}
@@ -0,0 +1,110 @@
+package linux
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"al.essio.dev/pkg/shellescape"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform/communicator/remote"
+	"github.com/spf13/cast"
+)
+
+const (
+	deleteStrategyRemove  = "remove"
+	deleteStrategyRecycle = "recycle"
+	deleteStrategyShred   = "shred"
+	deleteStrategyRetain  = "retain"
+)
+
+var deleteStrategies = []string{deleteStrategyRemove, deleteStrategyRecycle, deleteStrategyShred, deleteStrategyRetain}
+
+// deleteStrategy decides what happens to an existing path when linux.remove is called.
+type deleteStrategy interface {
+	delete(ctx context.Context, l *linux, path string) error
+}
+
+// newDeleteStrategy returns the strategy for name. Empty name recycles when the recycle bin is configured,
+// and removes otherwise.
+func newDeleteStrategy(name string, rb recycleBin) deleteStrategy {
+	switch name {
+	case "":
+		if rb.path != "" {
+			return deleteRecycle{bin: rb}
+		}
+		return deleteRemove{}
+	case deleteStrategyRecycle:
+		return deleteRecycle{bin: rb}
+	case deleteStrategyShred:
+		return deleteShred{}
+	case deleteStrategyRetain:
+		return deleteRetain{}
+	default:
+		return deleteRemove{}
+	}
+}
+
+// validateDeleteStrategy ensures the recycle strategy comes with a recycle bin.
+func validateDeleteStrategy(rd *schema.ResourceDiff, attrStrategy, attrRecyclePath string) error {
+	if cast.ToString(rd.Get(attrStrategy)) != deleteStrategyRecycle || !rd.NewValueKnown(attrRecyclePath) {
+		return nil
+	}
+	if cast.ToString(rd.Get(attrRecyclePath)) == "" {
+		return fmt.Errorf("'%s' is required when '%s' is '%s'", attrRecyclePath, attrStrategy, deleteStrategyRecycle)
+	}
+	return nil
+}
+
+// existGuard prefixes cmd with a check so that nothing is done when path doesn't exist.
+func existGuard(path, cmd string) string {
+	pathSafe := shellescape.Quote(path)
+	return fmt.Sprintf(`{ { [ ! -e %s ] && [ ! -L %s ] ;} || %s ;}`, pathSafe, pathSafe, cmd)
+}
+
+type deleteRemove struct{}
+
+func (deleteRemove) delete(ctx context.Context, l *linux, path string) error {
+	cmd := existGuard(path, shellescape.QuoteCommand([]string{"rm", "-rf", path}))
+	return l.exec(ctx, &remote.Cmd{Command: cmd})
+}
+
+// deleteRecycle moves path into a new generation of the recycle bin and prunes stale generations afterwards.
+type deleteRecycle struct {
+	bin recycleBin
+}
+
+func (s deleteRecycle) delete(ctx context.Context, l *linux, path string) (err error) {
+	if s.bin.path == "" {
+		return fmt.Errorf("recycle path is required to recycle '%s'", path)
+	}
+	generation := fmt.Sprintf("%s/%d", s.bin.path, time.Now().Unix())
+	cmd := existGuard(path, fmt.Sprintf(`{ %s && %s && printf '%%s\n' %s >> %s ;}`,
+		shellescape.QuoteCommand([]string{"mkdir", "-p", generation}),
+		shellescape.QuoteCommand([]string{"mv", path, generation}),
+		shellescape.Quote(path), shellescape.Quote(generation+"/"+recycleIndex),
+	))
+	if err = l.exec(ctx, &remote.Cmd{Command: cmd}); err != nil {
+		return
+	}
+	return l.pruneRecycleBin(ctx, s.bin)
+}
+
+// deleteShred overwrites every regular file under path before unlinking them. Symlinks are removed without
+// touching their targets.
+type deleteShred struct{}
+
+func (deleteShred) delete(ctx context.Context, l *linux, path string) error {
+	cmd := existGuard(path, fmt.Sprintf(`{ %s && %s ;}`,
+		shellescape.QuoteCommand([]string{"find", path, "-type", "f", "-exec", "shred", "-z", "-u", "--", "{}", "+"}),
+		shellescape.QuoteCommand([]string{"rm", "-rf", path}),
+	))
+	return l.exec(ctx, &remote.Cmd{Command: cmd})
+}
+
+// deleteRetain leaves path untouched so that it is only forgotten by terraform.
+type deleteRetain struct{}
+
+func (deleteRetain) delete(ctx context.Context, l *linux, path string) error {
+	return nil
+}
@@ -0,0 +1,334 @@
+package linux
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/spf13/cast"
+)
+
+const (
+	attrDirectoryContentsProviderOverride = "provider_override"
+	attrDirectoryContentsPath             = "path"
+	attrDirectoryContentsFile             = "file"
+	attrDirectoryContentsFileName         = "name"
+	attrDirectoryContentsFileContent      = "content"
+	attrDirectoryContentsFileOwner        = "owner"
+	attrDirectoryContentsFileGroup        = "group"
+	attrDirectoryContentsFileMode         = "mode"
+	attrDirectoryContentsRecyclePath      = "recycle_path"
+	attrDirectoryContentsRecycleRetention = "recycle_retention"
+	attrDirectoryContentsRecycleMaxItems  = "recycle_max_items"
+	attrDirectoryContentsPurgeUnmanaged   = "purge_unmanaged"
+	attrDirectoryContentsUnmanaged        = "unmanaged"
+	attrDirectoryContentsPendingPurge     = "pending_purge"
+)
+
+var schemaDirectoryContentsResource = map[string]*schema.Schema{
+	attrDirectoryContentsProviderOverride: {
+		Type:     schema.TypeList,
+		Optional: true,
+		MaxItems: 1,
+		Elem: &schema.Resource{
+			Schema: subSchemaProviderOverride,
+		},
+	},
+
+	attrDirectoryContentsPath: {
+		Type:     schema.TypeString,
+		Required: true,
+		ForceNew: true,
+	},
+	attrDirectoryContentsFile: {
+		Type:     schema.TypeSet,
+		Optional: true,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				attrDirectoryContentsFileName: {
+					Type:     schema.TypeString,
+					Required: true,
+					ValidateFunc: validation.All(
+						validation.StringDoesNotMatch(regexp.MustCompile(`/`), "must not contain '/'"),
+						validation.StringNotInSlice([]string{"", ".", ".."}, false),
+					),
+				},
+				attrDirectoryContentsFileContent: {
+					Type:     schema.TypeString,
+					Optional: true,
+					Default:  "",
+				},
+				attrDirectoryContentsFileOwner: {
+					Type:         schema.TypeInt,
+					Optional:     true,
+					Default:      0,
+					ValidateFunc: validateID,
+				},
+				attrDirectoryContentsFileGroup: {
+					Type:         schema.TypeInt,
+					Optional:     true,
+					Default:      0,
+					ValidateFunc: validateID,
+				},
+				attrDirectoryContentsFileMode: {
+					Type:         schema.TypeString,
+					Optional:     true,
+					Default:      "644",
+					ValidateFunc: validation.StringMatch(modeOctalRegex, "must be an octal mode"),
+					StateFunc:    func(v interface{}) string { return normalizeMode(cast.ToString(v)) },
+				},
+			},
+		},
+		Description: "Files directly inside the directory",
+	},
+	attrDirectoryContentsPurgeUnmanaged: {
+		Type:        schema.TypeBool,
+		Optional:    true,
+		Default:     false,
+		Description: "Whether entries inside the directory that are not declared in `file` will be removed",
+	},
+	attrDirectoryContentsRecyclePath: {
+		Type:        schema.TypeString,
+		Optional:    true,
+		Default:     "",
+		Description: "Path to parent directory of a generated-unix-timestamp directory where unmanaged entries will be placed",
+	},
+	attrDirectoryContentsRecycleRetention: {
+		Type:         schema.TypeString,
+		Optional:     true,
+		Default:      "",
+		ValidateFunc: validateRetention,
+		Description:  "Duration, e.g. `720h`, after which generations inside `recycle_path` are removed whenever another item is recycled",
+	},
+	attrDirectoryContentsRecycleMaxItems: {
+		Type:         schema.TypeInt,
+		Optional:     true,
+		Default:      0,
+		ValidateFunc: validation.IntAtLeast(0),
+		Description:  "Maximum number of generations kept inside `recycle_path`. The oldest ones are removed whenever another item is recycled",
+	},
+	attrDirectoryContentsUnmanaged: {
+		Type:        schema.TypeList,
+		Computed:    true,
+		Elem:        &schema.Schema{Type: schema.TypeString},
+		Description: "Names of entries inside the directory that are not declared in `file`",
+	},
+	attrDirectoryContentsPendingPurge: {
+		Type:        schema.TypeList,
+		Computed:    true,
+		Elem:        &schema.Schema{Type: schema.TypeString},
+		Description: "Names of unmanaged entries that will be removed by the planned apply",
+	},
+}
+
+type handlerDirectoryContentsResource struct{}
+
+func (handlerDirectoryContentsResource) newFiles(v interface{}) (files map[string]directoryContentFile) {
+	files = map[string]directoryContentFile{}
+	s, ok := v.(*schema.Set)
+	if !ok {
+		return
+	}
+	for _, e := range s.List() {
+		m := cast.ToStringMap(e)
+		f := directoryContentFile{
+			name:    cast.ToString(m[attrDirectoryContentsFileName]),
+			content: cast.ToString(m[attrDirectoryContentsFileContent]),
+			permission: permission{
+				owner: cast.ToUint32(m[attrDirectoryContentsFileOwner]),
+				group: cast.ToUint32(m[attrDirectoryContentsFileGroup]),
+				mode:  normalizeMode(cast.ToString(m[attrDirectoryContentsFileMode])),
+			},
+		}
+		files[f.name] = f
+	}
+	return
+}
+
+func (h handlerDirectoryContentsResource) newDirectoryContents(rd *schema.ResourceData) (dc *directoryContents) {
+	if rd == nil {
+		return
+	}
+	dc = &directoryContents{
+		path:    cast.ToString(rd.Get(attrDirectoryContentsPath)),
+		files:   h.newFiles(rd.Get(attrDirectoryContentsFile)),
+		recycle: newRecycleBin(rd, attrDirectoryContentsRecyclePath, attrDirectoryContentsRecycleRetention, attrDirectoryContentsRecycleMaxItems),
+	}
+	if cast.ToBool(rd.Get(attrDirectoryContentsPurgeUnmanaged)) {
+		dc.purge = cast.ToStringSlice(rd.Get(attrDirectoryContentsPendingPurge))
+		dc.purgeAll = h.pendingPurgeUnknown(rd)
+	}
+	return
+}
+
+// pendingPurgeUnknown reports whether pending_purge is planned as unknown, in which case every unmanaged entry found
+// during apply is removed.
+func (handlerDirectoryContentsResource) pendingPurgeUnknown(rd *schema.ResourceData) bool {
+	plan := rd.GetRawPlan()
+	if plan.IsNull() || !plan.IsKnown() || !plan.Type().HasAttribute(attrDirectoryContentsPendingPurge) {
+		return false
+	}
+	return !plan.GetAttr(attrDirectoryContentsPendingPurge).IsKnown()
+}
+
+func (h handlerDirectoryContentsResource) newDiffedDirectoryContents(rd *schema.ResourceData) (old, new *directoryContents) {
+	if rd == nil {
+		return
+	}
+	old, new = h.newDirectoryContents(rd), h.newDirectoryContents(rd)
+	o, n := rd.GetChange(attrDirectoryContentsFile)
+	old.files, new.files = h.newFiles(o), h.newFiles(n)
+	return
+}
+
+func (handlerDirectoryContentsResource) updateResourceData(dc *directoryContents, rd *schema.ResourceData) (err error) {
+	if dc == nil {
+		rd.SetId("")
+		return
+	}
+
+	names := make([]string, 0, len(dc.files))
+	for name := range dc.files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	files := make([]interface{}, 0, len(names))
+	for _, name := range names {
+		f := dc.files[name]
+		files = append(files, map[string]interface{}{
+			attrDirectoryContentsFileName:    f.name,
+			attrDirectoryContentsFileContent: f.content,
+			attrDirectoryContentsFileOwner:   int(f.permission.owner),
+			attrDirectoryContentsFileGroup:   int(f.permission.group),
+			attrDirectoryContentsFileMode:    f.permission.mode,
+		})
+	}
+	if err = rd.Set(attrDirectoryContentsFile, files); err != nil {
+		return
+	}
+	if err = rd.Set(attrDirectoryContentsUnmanaged, dc.unmanaged); err != nil {
+		return
+	}
+	if err = rd.Set(attrDirectoryContentsPendingPurge, []string{}); err != nil { // only known during plan
+		return
+	}
+	return
+}
+
+func (h handlerDirectoryContentsResource) Read(ctx context.Context, rd *schema.ResourceData, meta interface{}) (d diag.Diagnostics) {
+	l, err := getLinux(meta.(*linuxPool), rd)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	dc, err := l.readDirectoryContents(ctx, cast.ToString(rd.Get(attrDirectoryContentsPath)),
+		h.newFiles(rd.Get(attrDirectoryContentsFile)))
+	if errors.Is(err, errPathNotExist) {
+		rd.SetId("")
+		return
+	}
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err = h.updateResourceData(dc, rd); err != nil {
+		return diag.FromErr(err)
+	}
+	return
+}
+
+func (h handlerDirectoryContentsResource) Create(ctx context.Context, rd *schema.ResourceData, meta interface{}) (d diag.Diagnostics) {
+	l, err := getLinux(meta.(*linuxPool), rd)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if err := l.updateDirectoryContents(ctx, nil, h.newDirectoryContents(rd)); err != nil {
+		return diag.FromErr(err)
+	}
+
+	id, err := uuid.NewRandom()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	rd.SetId(id.String())
+	return h.Read(ctx, rd, meta)
+}
+
+func (h handlerDirectoryContentsResource) Update(ctx context.Context, rd *schema.ResourceData, meta interface{}) (d diag.Diagnostics) {
+	l, err := getLinux(meta.(*linuxPool), rd)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	old, new := h.newDiffedDirectoryContents(rd)
+	if err = l.updateDirectoryContents(ctx, old, new); err != nil {
+		_ = h.updateResourceData(old, rd) // WARN: see https://github.com/hashicorp/terraform-plugin-sdk/issues/476
+		return diag.FromErr(err)
+	}
+	return h.Read(ctx, rd, meta)
+}
+
+func (h handlerDirectoryContentsResource) Delete(ctx context.Context, rd *schema.ResourceData, meta interface{}) (d diag.Diagnostics) {
+	l, err := getLinux(meta.(*linuxPool), rd)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := l.deleteDirectoryContents(ctx, h.newDirectoryContents(rd)); err != nil {
+		return diag.FromErr(err)
+	}
+	return
+}
+
+// CustomizeDiff plans the removal of unmanaged entries when purge_unmanaged is set. The entries are taken from the
+// refreshed state, or listed from remote when the resource is about to be created.
+func (h handlerDirectoryContentsResource) CustomizeDiff(ctx context.Context, rd *schema.ResourceDiff, meta interface{}) (err error) {
+	if !cast.ToBool(rd.Get(attrDirectoryContentsPurgeUnmanaged)) {
+		return
+	}
+	unmanaged := cast.ToStringSlice(rd.Get(attrDirectoryContentsUnmanaged))
+	if rd.Id() == "" {
+		if !rd.NewValueKnown(attrDirectoryContentsPath) || !rd.NewValueKnown(attrDirectoryContentsProviderOverride) {
+			return rd.SetNewComputed(attrDirectoryContentsPendingPurge)
+		}
+		l, err := getLinux(meta.(*linuxPool), rd)
+		if err != nil {
+			return err
+		}
+		dc, err := l.readDirectoryContents(ctx, cast.ToString(rd.Get(attrDirectoryContentsPath)), map[string]directoryContentFile{})
+		if errors.Is(err, errPathNotExist) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		unmanaged = dc.unmanaged
+	}
+	if len(unmanaged) == 0 {
+		return
+	}
+	if err = rd.SetNew(attrDirectoryContentsPendingPurge, unmanaged); err != nil {
+		return
+	}
+	if rd.Id() != "" {
+		err = rd.SetNew(attrDirectoryContentsUnmanaged, []string{})
+	}
+	return
+}
+
+func directoryContentsResource() *schema.Resource {
+	var hdcr handlerDirectoryContentsResource
+	return &schema.Resource{
+		Schema:        schemaDirectoryContentsResource,
+		CreateContext: hdcr.Create,
+		ReadContext:   hdcr.Read,
+		UpdateContext: hdcr.Update,
+		DeleteContext: hdcr.Delete,
+		CustomizeDiff: hdcr.CustomizeDiff,
+	}
+}
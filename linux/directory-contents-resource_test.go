@@ -0,0 +1,135 @@
+package linux
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccLinuxDirectoryContentsBasic(t *testing.T) {
+	conf0 := tfConf{
+		Provider: testAccProvider,
+		Extra: tfmap{
+			"path":         fmt.Sprintf(`"/tmp/linux/%s"`, acctest.RandString(16)),
+			"recycle_path": fmt.Sprintf(`"/tmp/linux/recycle-%s"`, acctest.RandString(16)),
+			"files":        `[{ name = "a", content = "a", mode = "600" }, { name = "b", content = "b" }]`,
+			"expected":     `"a b stray"`,
+		},
+	}
+	conf1 := conf0.Copy(func(tc *tfConf) {
+		tc.Extra.With("purge", "true")
+		tc.Extra.With("expected", `"a b"`)
+	})
+	conf2 := conf1.Copy(func(tc *tfConf) {
+		tc.Extra.With("files", `[{ name = "b", content = "b1" }, { name = "c", content = "c" }]`)
+		tc.Extra.With("expected", `"b c"`)
+	})
+
+	resource.Test(t, resource.TestCase{
+		ExternalProviders: map[string]resource.ExternalProvider{"null": {}},
+		PreCheck:          testAccPreCheckConnection(t),
+		Providers:         testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccLinuxDirectoryContentsBasicConfig(t, conf0),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("linux_directory_contents.contents", "file.#", "2"),
+					resource.TestCheckResourceAttr("linux_directory_contents.contents", "unmanaged.#", "1"),
+					resource.TestCheckResourceAttr("linux_directory_contents.contents", "unmanaged.0", "stray"),
+					resource.TestCheckResourceAttr("linux_directory_contents.contents", "pending_purge.#", "0"),
+				),
+			},
+			{
+				Config: testAccLinuxDirectoryContentsBasicConfig(t, conf1),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("linux_directory_contents.contents", "file.#", "2"),
+					resource.TestCheckResourceAttr("linux_directory_contents.contents", "unmanaged.#", "0"),
+				),
+			},
+			{
+				Config: testAccLinuxDirectoryContentsBasicConfig(t, conf2),
+			},
+		},
+	})
+}
+
+func testAccLinuxDirectoryContentsBasicConfig(t *testing.T, conf tfConf) (s string) {
+	tf := heredoc.Doc(`
+		provider "linux" {
+			alias = "test"
+		    {{- .Provider.Serialize | nindent 4 }}
+		}
+
+		resource "null_resource" "stray" {
+		    triggers = {
+		        path = {{ .Extra.path }}
+		        recycle_path = {{ .Extra.recycle_path }}
+		    }
+		    connection {
+		        type = "ssh"
+		        {{- .Provider.Serialize | nindent 8 }}
+		    }
+		    provisioner "remote-exec" {
+		        inline = [
+		            <<-EOF
+		                mkdir -p '${self.triggers["path"]}'
+		                printf 'stray' > '${self.triggers["path"]}/stray'
+		            EOF
+		        ]
+		    }
+		    provisioner "remote-exec" {
+		        when = destroy
+		        inline = [
+		            <<-EOF
+		                [ "$(cat '${self.triggers["recycle_path"]}'/*/stray)" == "stray" ] || exit 100
+		                rm -rf '${self.triggers["path"]}' '${self.triggers["recycle_path"]}'
+		            EOF
+		        ]
+		    }
+		}
+
+		resource "linux_directory_contents" "contents" {
+			provider = linux.test
+		    depends_on = [ null_resource.stray ]
+
+		    path = {{ .Extra.path }}
+		    recycle_path = {{ .Extra.recycle_path }}
+		    purge_unmanaged = {{ .Extra.purge | default "false" }}
+		    dynamic "file" {
+		        for_each = {{ .Extra.files }}
+		        content {
+		            name = file.value.name
+		            content = file.value.content
+		            mode = lookup(file.value, "mode", "644")
+		        }
+		    }
+		}
+
+		resource "null_resource" "create_validator" {
+		    triggers = {
+		        id = linux_directory_contents.contents.id
+		        files = jsonencode(linux_directory_contents.contents.file)
+		        unmanaged = jsonencode(linux_directory_contents.contents.unmanaged)
+		    }
+		    connection {
+		        type = "ssh"
+		        {{- .Provider.Serialize | nindent 8 }}
+		    }
+		    provisioner "remote-exec" {
+		        inline = [
+		            <<-EOF
+		                [ "$(ls -A {{ .Extra.path }} | xargs)" == {{ .Extra.expected }} ] || exit 101
+		            EOF
+		        ]
+		    }
+		}
+	`)
+	s, err := conf.compile(tf)
+	t.Log(s)
+	require.NoError(t, err, "compile template failed")
+	return
+}
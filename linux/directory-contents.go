@@ -0,0 +1,143 @@
+package linux
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"al.essio.dev/pkg/shellescape"
+	"github.com/hashicorp/terraform/communicator/remote"
+)
+
+type directoryContentFile struct {
+	name       string
+	content    string
+	permission permission
+}
+
+func (f directoryContentFile) sha256() string {
+	sum := sha256.Sum256([]byte(f.content))
+	return hex.EncodeToString(sum[:])
+}
+
+// directoryContents holds the full set of files directly inside a directory.
+// Any other entry inside the directory is considered unmanaged, and is only removed when it is listed in purge,
+// or when purgeAll is set.
+type directoryContents struct {
+	path     string
+	files    map[string]directoryContentFile
+	recycle  recycleBin
+	purge    []string
+	purgeAll bool
+
+	unmanaged []string
+}
+
+func (l *linux) readDirectoryContents(ctx context.Context, path string, managed map[string]directoryContentFile) (dc *directoryContents, err error) {
+	if _, err = l.getPermission(ctx, path); err != nil {
+		return
+	}
+	entries, err := l.listDirectory(ctx, path, "", 1)
+	if err != nil {
+		return
+	}
+
+	dc = &directoryContents{path: path, files: map[string]directoryContentFile{}, unmanaged: []string{}}
+	for _, e := range entries {
+		f, ok := managed[e.name]
+		if !ok || e.kind != directoryEntryFile {
+			dc.unmanaged = append(dc.unmanaged, e.name)
+			continue
+		}
+		if e.sha256 != f.sha256() {
+			if f.content, err = l.cat(ctx, filepath.Join(path, e.name)); err != nil {
+				return
+			}
+		}
+		f.permission = permission{owner: e.owner, group: e.group, mode: e.mode}
+		dc.files[e.name] = f
+	}
+	return
+}
+
+// writeDirectoryContentFile uploads the file to a temporary sibling and renames it after its permission is set,
+// so that the file is never observed partially written or with a different permission.
+func (l *linux) writeDirectoryContentFile(ctx context.Context, path string, f directoryContentFile) (err error) {
+	dst := filepath.Join(path, f.name)
+	tmp := filepath.Join(path, fmt.Sprintf(".%s.%d.tmp", f.name, time.Now().UnixNano()))
+	if err = l.upload(ctx, tmp, strings.NewReader(f.content)); err != nil {
+		return
+	}
+	if err = l.setPermission(ctx, tmp, &f.permission); err == nil {
+		err = l.exec(ctx, &remote.Cmd{Command: shellescape.QuoteCommand([]string{"mv", "-f", tmp, dst})})
+	}
+	if err != nil {
+		_ = l.exec(ctx, &remote.Cmd{Command: shellescape.QuoteCommand([]string{"rm", "-f", tmp})})
+	}
+	return
+}
+
+// updateDirectoryContents moves the unmanaged entries to be purged into the recycle path, and then writes files of new
+// that differ from old. An entry that has the name of a file of new but is not a regular file is never replaced unless
+// it is purged.
+func (l *linux) updateDirectoryContents(ctx context.Context, old, new *directoryContents) (err error) {
+	if new == nil {
+		return errNil
+	}
+	if err = l.mkdirp(ctx, new.path); err != nil {
+		return
+	}
+
+	entries, err := l.listDirectory(ctx, new.path, "", 1)
+	if err != nil {
+		return
+	}
+	for _, e := range entries {
+		_, declared := new.files[e.name]
+		if declared && e.kind == directoryEntryFile {
+			continue
+		}
+		if !new.purgeAll && !slices.Contains(new.purge, e.name) {
+			if declared {
+				return fmt.Errorf("'%s' exists and is not a regular file, set `purge_unmanaged` to replace it", e.name)
+			}
+			continue
+		}
+		if err = l.remove(ctx, filepath.Join(new.path, e.name), newDeleteStrategy("", new.recycle)); err != nil {
+			return fmt.Errorf("while removing unmanaged '%s': %w", e.name, err)
+		}
+	}
+
+	names := make([]string, 0, len(new.files))
+	for name := range new.files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if old != nil && old.files[name] == new.files[name] {
+			continue
+		}
+		if err = l.writeDirectoryContentFile(ctx, new.path, new.files[name]); err != nil {
+			return fmt.Errorf("while writing '%s': %w", name, err)
+		}
+	}
+	return
+}
+
+func (l *linux) deleteDirectoryContents(ctx context.Context, dc *directoryContents) (err error) {
+	if dc == nil {
+		return
+	}
+	for name := range dc.files {
+		if err = l.remove(ctx, filepath.Join(dc.path, name), newDeleteStrategy("", dc.recycle)); err != nil {
+			return
+		}
+	}
+	return
+}
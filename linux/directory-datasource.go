@@ -0,0 +1,172 @@
+package linux
+
+import (
+	"context"
+	"errors"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/spf13/cast"
+)
+
+const (
+	attrDirectoryDataProviderOverride = "provider_override"
+	attrDirectoryDataPath             = "path"
+	attrDirectoryDataRecursive        = "recursive"
+	attrDirectoryDataPattern          = "pattern"
+	attrDirectoryDataMaxDepth         = "max_depth"
+	attrDirectoryDataExists           = "exists"
+	attrDirectoryDataEntries          = "entries"
+	attrDirectoryDataEntryName        = "name"
+	attrDirectoryDataEntryType        = "type"
+	attrDirectoryDataEntrySize        = "size"
+	attrDirectoryDataEntryMode        = "mode"
+	attrDirectoryDataEntryOwner       = "owner"
+	attrDirectoryDataEntryGroup       = "group"
+	attrDirectoryDataEntrySHA256      = "sha256"
+)
+
+var schemaDirectoryDataSource = map[string]*schema.Schema{
+	attrDirectoryDataProviderOverride: {
+		Type:     schema.TypeList,
+		Optional: true,
+		MaxItems: 1,
+		Elem: &schema.Resource{
+			Schema: subSchemaProviderOverride,
+		},
+	},
+
+	attrDirectoryDataPath: {
+		Type:     schema.TypeString,
+		Required: true,
+	},
+	attrDirectoryDataRecursive: {
+		Type:        schema.TypeBool,
+		Optional:    true,
+		Default:     false,
+		Description: "If true, entries of sub directories are listed as well",
+	},
+	attrDirectoryDataPattern: {
+		Type:        schema.TypeString,
+		Optional:    true,
+		Default:     "",
+		Description: "Glob pattern matched against the base name of each entry",
+	},
+	attrDirectoryDataMaxDepth: {
+		Type:         schema.TypeInt,
+		Optional:     true,
+		Default:      0,
+		ValidateFunc: validation.IntAtLeast(0),
+		Description:  "Maximum depth of sub directories to descend when `recursive` is true. 0 means unlimited",
+	},
+	attrDirectoryDataExists: {
+		Type:        schema.TypeBool,
+		Computed:    true,
+		Description: "Whether the directory exists. `entries` is empty when it doesn't",
+	},
+	attrDirectoryDataEntries: {
+		Type:     schema.TypeList,
+		Computed: true,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				attrDirectoryDataEntryName: {
+					Type:        schema.TypeString,
+					Computed:    true,
+					Description: "Path relative to `path`",
+				},
+				attrDirectoryDataEntryType: {
+					Type:        schema.TypeString,
+					Computed:    true,
+					Description: "One of `file`, `directory`, `symlink` or `other`",
+				},
+				attrDirectoryDataEntrySize: {
+					Type:     schema.TypeInt,
+					Computed: true,
+				},
+				attrDirectoryDataEntryMode: {
+					Type:     schema.TypeString,
+					Computed: true,
+				},
+				attrDirectoryDataEntryOwner: {
+					Type:     schema.TypeInt,
+					Computed: true,
+				},
+				attrDirectoryDataEntryGroup: {
+					Type:     schema.TypeInt,
+					Computed: true,
+				},
+				attrDirectoryDataEntrySHA256: {
+					Type:        schema.TypeString,
+					Computed:    true,
+					Description: "Hex encoded SHA256 checksum of the content. Empty for non regular files",
+				},
+			},
+		},
+	},
+}
+
+type handlerDirectoryDataSource struct{}
+
+func (handlerDirectoryDataSource) updateResourceData(entries []directoryEntry, rd *schema.ResourceData) (err error) {
+	list := make([]interface{}, 0, len(entries))
+	for _, e := range entries {
+		list = append(list, map[string]interface{}{
+			attrDirectoryDataEntryName:   e.name,
+			attrDirectoryDataEntryType:   e.kind,
+			attrDirectoryDataEntrySize:   e.size,
+			attrDirectoryDataEntryMode:   e.mode,
+			attrDirectoryDataEntryOwner:  e.owner,
+			attrDirectoryDataEntryGroup:  e.group,
+			attrDirectoryDataEntrySHA256: e.sha256,
+		})
+	}
+	if err = rd.Set(attrDirectoryDataEntries, list); err != nil {
+		return
+	}
+	return rd.Set(attrDirectoryDataExists, true)
+}
+
+func (h handlerDirectoryDataSource) Read(ctx context.Context, rd *schema.ResourceData, meta interface{}) (d diag.Diagnostics) {
+	l, err := getLinux(meta.(*linuxPool), rd)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	rd.SetId("static")
+	path := cast.ToString(rd.Get(attrDirectoryDataPath))
+	_, err = l.getPermission(ctx, path)
+	if errors.Is(err, errPathNotExist) {
+		if err = rd.Set(attrDirectoryDataEntries, []interface{}{}); err != nil {
+			return diag.FromErr(err)
+		}
+		if err = rd.Set(attrDirectoryDataExists, false); err != nil {
+			return diag.FromErr(err)
+		}
+		return
+	}
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	maxDepth := 1
+	if cast.ToBool(rd.Get(attrDirectoryDataRecursive)) {
+		maxDepth = cast.ToInt(rd.Get(attrDirectoryDataMaxDepth))
+	}
+	entries, err := l.listDirectory(ctx, path, cast.ToString(rd.Get(attrDirectoryDataPattern)), maxDepth)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if err = h.updateResourceData(entries, rd); err != nil {
+		return diag.FromErr(err)
+	}
+	return
+}
+
+func directoryDataSource() *schema.Resource {
+	h := handlerDirectoryDataSource{}
+	return &schema.Resource{
+		Schema:      schemaDirectoryDataSource,
+		ReadContext: h.Read,
+	}
+}
@@ -0,0 +1,97 @@
+package linux
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccLinuxDataDirectoryBasic(t *testing.T) {
+	conf := tfConf{
+		Provider: testAccProvider,
+		Extra: tfmap{
+			"path": fmt.Sprintf(`"/tmp/linux/%s"`, acctest.RandString(16)),
+		},
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  testAccPreCheckConnection(t),
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccLinuxDataDirectoryBasicConfig(t, conf),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.linux_directory.flat", "entries.#", "2"),
+					resource.TestCheckResourceAttr("data.linux_directory.flat", "entries.0.name", "a.crt"),
+					resource.TestCheckResourceAttr("data.linux_directory.flat", "entries.0.type", "file"),
+					resource.TestCheckResourceAttr("data.linux_directory.flat", "entries.0.mode", "600"),
+					resource.TestCheckResourceAttr("data.linux_directory.flat", "entries.0.size", "10"),
+					resource.TestCheckResourceAttr("data.linux_directory.flat", "entries.0.sha256",
+						"936a185caaa266bb9cbe981e9e05cb78cd732b0b3280eb944412bb6f8f8f07af"),
+					resource.TestCheckResourceAttr("data.linux_directory.flat", "entries.1.name", "sub"),
+					resource.TestCheckResourceAttr("data.linux_directory.flat", "entries.1.type", "directory"),
+					resource.TestCheckResourceAttr("data.linux_directory.flat", "entries.1.sha256", ""),
+					resource.TestCheckResourceAttr("data.linux_directory.recursive", "entries.#", "2"),
+					resource.TestCheckResourceAttr("data.linux_directory.recursive", "entries.1.name", "sub/b.crt"),
+					resource.TestCheckResourceAttr("data.linux_directory.notexist", "exists", "false"),
+					resource.TestCheckResourceAttr("data.linux_directory.notexist", "entries.#", "0"),
+				),
+			},
+		},
+	})
+}
+
+func testAccLinuxDataDirectoryBasicConfig(t *testing.T, conf tfConf) (s string) {
+	tf := heredoc.Doc(`
+		provider "linux" {
+			alias = "test"
+		    {{- .Provider.Serialize | nindent 4 }}
+		}
+
+		resource "linux_file" "a" {
+			provider = "linux.test"
+
+		    path = "${ {{- .Extra.path -}} }/a.crt"
+		    content = "helloworld"
+		    mode = "600"
+		}
+
+		resource "linux_file" "b" {
+			provider = "linux.test"
+
+		    path = "${ {{- .Extra.path -}} }/sub/b.crt"
+		    content = "helloworld"
+		}
+
+		data "linux_directory" "flat" {
+			provider = "linux.test"
+		    depends_on = [ linux_file.a, linux_file.b ]
+
+		    path = {{ .Extra.path }}
+		}
+
+		data "linux_directory" "recursive" {
+			provider = "linux.test"
+		    depends_on = [ linux_file.a, linux_file.b ]
+
+		    path = {{ .Extra.path }}
+		    recursive = true
+		    pattern = "*.crt"
+		}
+
+		data "linux_directory" "notexist" {
+			provider = "linux.test"
+
+		    path = "${ {{- .Extra.path -}} }/notexist"
+		}
+	`)
+
+	s, err := conf.compile(tf)
+	t.Log(s)
+	require.NoError(t, err, "compile template failed")
+	return
+}
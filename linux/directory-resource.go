@@ -3,7 +3,7 @@ package linux
 import (
 	"context"
 	"errors"
-	"regexp"
+	"reflect"
 
 	"github.com/google/uuid"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
@@ -13,13 +13,38 @@ import (
 )
 
 const (
-	attrDirectoryProviderOverride = "provider_override"
-	attrDirectoryPath             = "path"
-	attrDirectoryOwner            = "owner"
-	attrDirectoryGroup            = "group"
-	attrDirectoryMode             = "mode"
-	attrDirectoryOverwrite        = "overwrite"
-	attrDirectoryRecyclePath      = "recycle_path"
+	attrDirectoryProviderOverride   = "provider_override"
+	attrDirectoryPath               = "path"
+	attrDirectoryOwner              = "owner"
+	attrDirectoryGroup              = "group"
+	attrDirectoryOwnerName          = "owner_name"
+	attrDirectoryGroupName          = "group_name"
+	attrDirectoryMode               = "mode"
+	attrDirectoryOverwrite          = "overwrite"
+	attrDirectoryRecyclePath        = "recycle_path"
+	attrDirectoryRecycleRetention   = "recycle_retention"
+	attrDirectoryRecycleMaxItems    = "recycle_max_items"
+	attrDirectoryRestoreFromRecycle = "restore_from_recycle"
+	attrDirectoryDeleteStrategy     = "delete_strategy"
+	attrDirectoryOnCreate           = "on_create"
+	attrDirectoryOnChange           = "on_change"
+	attrDirectoryOnDestroy          = "on_destroy"
+	attrDirectoryHookWorkdir        = "hook_working_directory"
+	attrDirectoryHookEnvironment    = "hook_environment"
+	attrDirectoryBackup             = "backup"
+	attrDirectoryBackupPath         = "backup_path"
+	attrDirectoryBackupLocation     = "backup_location"
+	attrDirectoryBackupLocations    = "backup_locations"
+	attrDirectorySelinuxContext     = "selinux_context"
+	attrDirectoryACL                = "acl"
+	attrDirectoryXattrs             = "xattrs"
+	attrDirectoryRecursiveOwner     = "recursive_owner"
+	attrDirectoryRecursiveGroup     = "recursive_group"
+	attrDirectoryFileMode           = "file_mode"
+	attrDirectoryDirMode            = "dir_mode"
+	attrDirectorySource             = "source"
+	attrDirectorySourcePurge        = "source_purge"
+	attrDirectorySourceManifest     = "source_manifest"
 )
 
 var schemaDirectoryResource = map[string]*schema.Schema{
@@ -38,20 +63,37 @@ var schemaDirectoryResource = map[string]*schema.Schema{
 	},
 
 	attrDirectoryOwner: {
-		Type:     schema.TypeInt,
-		Optional: true,
-		Default:  0,
+		Type:         schema.TypeInt,
+		Optional:     true,
+		Computed:     true,
+		ValidateFunc: validateID,
+	},
+	attrDirectoryOwnerName: {
+		Type:          schema.TypeString,
+		Optional:      true,
+		Computed:      true,
+		ConflictsWith: []string{attrDirectoryOwner},
+		Description:   "User name of the directory, resolved on remote. Conflicts with `owner`",
 	},
 	attrDirectoryGroup: {
-		Type:     schema.TypeInt,
-		Optional: true,
-		Default:  0,
+		Type:         schema.TypeInt,
+		Optional:     true,
+		Computed:     true,
+		ValidateFunc: validateID,
+	},
+	attrDirectoryGroupName: {
+		Type:          schema.TypeString,
+		Optional:      true,
+		Computed:      true,
+		ConflictsWith: []string{attrDirectoryGroup},
+		Description:   "Group name of the directory, resolved on remote. Conflicts with `group`",
 	},
 	attrDirectoryMode: {
-		Type:         schema.TypeString,
-		Optional:     true,
-		Default:      "755",
-		ValidateFunc: validation.StringMatch(regexp.MustCompile("[0-7]{3}"), "Invalid linux permission"),
+		Type:             schema.TypeString,
+		Optional:         true,
+		Computed:         true,
+		ValidateFunc:     validateMode,
+		DiffSuppressFunc: diffSuppressMode(true),
 	},
 	attrDirectoryOverwrite: {
 		Type:        schema.TypeBool,
@@ -65,24 +107,210 @@ var schemaDirectoryResource = map[string]*schema.Schema{
 		Default:     "",
 		Description: "Path to parent directory of a generated-unix-timestamp directory where the directory will be placed on destroy",
 	},
+	attrDirectoryRecycleRetention: {
+		Type:         schema.TypeString,
+		Optional:     true,
+		Default:      "",
+		ValidateFunc: validateRetention,
+		Description:  "Duration, e.g. `720h`, after which generations inside `recycle_path` are removed whenever another item is recycled",
+	},
+	attrDirectoryRecycleMaxItems: {
+		Type:         schema.TypeInt,
+		Optional:     true,
+		Default:      0,
+		ValidateFunc: validation.IntAtLeast(0),
+		Description:  "Maximum number of generations kept inside `recycle_path`. The oldest ones are removed whenever another item is recycled",
+	},
+	attrDirectoryDeleteStrategy: {
+		Type:         schema.TypeString,
+		Optional:     true,
+		Default:      "",
+		ValidateFunc: validation.StringInSlice(deleteStrategies, false),
+		Description:  "What happens to the directory on destroy, one of `remove`, `recycle`, `shred` or `retain`. Default to `recycle` when `recycle_path` is set, otherwise `remove`",
+	},
+	attrDirectoryRestoreFromRecycle: {
+		Type:        schema.TypeBool,
+		Optional:    true,
+		Default:     false,
+		Description: "If true, the latest directory recycled from the same path inside `recycle_path` will be restored on create",
+	},
+	attrDirectoryOnCreate: {
+		Type:        schema.TypeString,
+		Optional:    true,
+		Default:     "",
+		Description: "Commands that will be executed after the directory is created",
+	},
+	attrDirectoryOnChange: {
+		Type:        schema.TypeString,
+		Optional:    true,
+		Default:     "",
+		Description: "Commands that will be executed after the directory path or permission is changed",
+	},
+	attrDirectoryOnDestroy: {
+		Type:        schema.TypeString,
+		Optional:    true,
+		Default:     "",
+		Description: "Commands that will be executed after the directory is destroyed",
+	},
+	attrDirectoryHookWorkdir: {
+		Type:        schema.TypeString,
+		Optional:    true,
+		Default:     ".",
+		Description: "The working directory where hook commands are executed",
+	},
+	attrDirectoryHookEnvironment: {
+		Type:        schema.TypeMap,
+		Optional:    true,
+		Elem:        schema.TypeString,
+		Description: "Environment variables that will be available to hook commands",
+	},
+	attrDirectoryBackup: {
+		Type:        schema.TypeBool,
+		Optional:    true,
+		Default:     false,
+		Description: "If true, existing directory on remote will be copied to a generated-unix-timestamp-suffixed path before being replaced on create or update",
+	},
+	attrDirectoryBackupPath: {
+		Type:        schema.TypeString,
+		Optional:    true,
+		Default:     "",
+		Description: "Path to directory where the backup will be placed. Default to the parent directory of the directory. Setting this implies `backup` to be true",
+	},
+	attrDirectoryBackupLocation: {
+		Type:        schema.TypeString,
+		Computed:    true,
+		Description: "Path of the first backup created by the latest create or update that backed up anything",
+	},
+	attrDirectoryBackupLocations: {
+		Type:        schema.TypeList,
+		Computed:    true,
+		Elem:        &schema.Schema{Type: schema.TypeString},
+		Description: "Paths of all backups created by the latest create or update that backed up anything",
+	},
+	attrDirectorySelinuxContext: {
+		Type:        schema.TypeString,
+		Optional:    true,
+		Computed:    true,
+		Description: "SELinux security context of the directory, e.g. `system_u:object_r:httpd_sys_content_t:s0`",
+	},
+	attrDirectoryACL: {
+		Type:        schema.TypeSet,
+		Optional:    true,
+		Computed:    true,
+		Elem:        &schema.Schema{Type: schema.TypeString},
+		Description: "POSIX ACL entries of the directory as printed by `getfacl`, excluding the base entries",
+	},
+	attrDirectoryXattrs: {
+		Type:             schema.TypeMap,
+		Optional:         true,
+		Computed:         true,
+		Elem:             &schema.Schema{Type: schema.TypeString},
+		ValidateDiagFunc: validateXattrs,
+		Description:      "Extended attributes of the directory in the `user.` namespace",
+	},
+	attrDirectoryRecursiveOwner: {
+		Type:        schema.TypeBool,
+		Optional:    true,
+		Default:     false,
+		Description: "If true, the owner of the directory will be applied to all of its descendants",
+	},
+	attrDirectoryRecursiveGroup: {
+		Type:        schema.TypeBool,
+		Optional:    true,
+		Default:     false,
+		Description: "If true, the group of the directory will be applied to all of its descendants",
+	},
+	attrDirectoryFileMode: {
+		Type:             schema.TypeString,
+		Optional:         true,
+		Default:          "",
+		ValidateFunc:     validation.StringMatch(modeOctalRegex, "must be an octal mode"),
+		DiffSuppressFunc: diffSuppressMode(false),
+		Description:      "Octal mode applied to all files under the directory",
+	},
+	attrDirectoryDirMode: {
+		Type:             schema.TypeString,
+		Optional:         true,
+		Default:          "",
+		ValidateFunc:     validation.StringMatch(modeOctalRegex, "must be an octal mode"),
+		DiffSuppressFunc: diffSuppressMode(false),
+		Description:      "Octal mode applied to all sub directories of the directory",
+	},
+	attrDirectorySource: {
+		Type:        schema.TypeString,
+		Optional:    true,
+		Default:     "",
+		Description: "Path to a local directory whose content will be uploaded recursively into the directory",
+	},
+	attrDirectorySourcePurge: {
+		Type:        schema.TypeBool,
+		Optional:    true,
+		Default:     false,
+		Description: "If true, entries inside the directory that don't exist in `source` will be removed",
+	},
+	attrDirectorySourceManifest: {
+		Type:        schema.TypeMap,
+		Computed:    true,
+		Elem:        &schema.Schema{Type: schema.TypeString},
+		Description: "SHA256 checksums of files uploaded from `source`, keyed by their relative path",
+	},
 }
 
 type handlerDirectoryResource struct{}
 
-func (handlerDirectoryResource) newDirectory(rd *schema.ResourceData) (d *directory) {
+func (handlerDirectoryResource) newHook(rd *schema.ResourceData) hook {
+	return hook{
+		onCreate:  cast.ToString(rd.Get(attrDirectoryOnCreate)),
+		onChange:  cast.ToString(rd.Get(attrDirectoryOnChange)),
+		onDestroy: cast.ToString(rd.Get(attrDirectoryOnDestroy)),
+		workdir:   cast.ToString(rd.Get(attrDirectoryHookWorkdir)),
+		env:       cast.ToStringMapString(rd.Get(attrDirectoryHookEnvironment)),
+	}
+}
+
+func (h handlerDirectoryResource) newDirectory(rd *schema.ResourceData) (d *directory) {
 	if rd == nil {
 		return
 	}
 	d = &directory{
 		path: cast.ToString(rd.Get(attrDirectoryPath)),
 		permission: permission{
-			owner: cast.ToUint16(rd.Get(attrDirectoryOwner)),
-			group: cast.ToUint16(rd.Get(attrDirectoryGroup)),
-			mode:  cast.ToString(rd.Get(attrDirectoryMode)),
+			owner: cast.ToUint32(rd.Get(attrDirectoryOwner)),
+			group: cast.ToUint32(rd.Get(attrDirectoryGroup)),
+
+			ownerName: cast.ToString(rd.Get(attrDirectoryOwnerName)),
+			groupName: cast.ToString(rd.Get(attrDirectoryGroupName)),
+			mode:      cast.ToString(rd.Get(attrDirectoryMode)),
 		},
-		overwrite:   cast.ToBool(rd.Get(attrDirectoryOverwrite)),
-		recyclePath: cast.ToString(rd.Get(attrDirectoryRecyclePath)),
+		overwrite:      cast.ToBool(rd.Get(attrDirectoryOverwrite)),
+		recycle:        newRecycleBin(rd, attrDirectoryRecyclePath, attrDirectoryRecycleRetention, attrDirectoryRecycleMaxItems),
+		deleteStrategy: cast.ToString(rd.Get(attrDirectoryDeleteStrategy)),
+		restore:        cast.ToBool(rd.Get(attrDirectoryRestoreFromRecycle)),
+		hook:           h.newHook(rd),
+
+		recursive: directoryRecursive{
+			owner:    cast.ToBool(rd.Get(attrDirectoryRecursiveOwner)),
+			group:    cast.ToBool(rd.Get(attrDirectoryRecursiveGroup)),
+			fileMode: normalizeMode(cast.ToString(rd.Get(attrDirectoryFileMode))),
+			dirMode:  normalizeMode(cast.ToString(rd.Get(attrDirectoryDirMode))),
+		},
+
+		source:         cast.ToString(rd.Get(attrDirectorySource)),
+		sourcePurge:    cast.ToBool(rd.Get(attrDirectorySourcePurge)),
+		sourceManifest: cast.ToStringMapString(rd.Get(attrDirectorySourceManifest)),
+
+		backup:          cast.ToBool(rd.Get(attrDirectoryBackup)),
+		backupPath:      cast.ToString(rd.Get(attrDirectoryBackupPath)),
+		backupLocation:  cast.ToString(rd.Get(attrDirectoryBackupLocation)),
+		backupLocations: cast.ToStringSlice(rd.Get(attrDirectoryBackupLocations)),
 	}
+	d.attributes = unmanagedAttributes(rd.GetRawConfig(), attributes{
+		selinuxContext: cast.ToString(rd.Get(attrDirectorySelinuxContext)),
+		acl:            aclFromSet(rd.Get(attrDirectoryACL)),
+		xattrs:         cast.ToStringMapString(rd.Get(attrDirectoryXattrs)),
+	}, attrDirectorySelinuxContext, attrDirectoryACL, attrDirectoryXattrs)
+	d.permission = unmanagedPermission(rd.GetRawConfig(), d.permission,
+		attrDirectoryOwner, attrDirectoryOwnerName, attrDirectoryGroup, attrDirectoryGroupName, attrDirectoryMode)
 	return
 }
 
@@ -96,10 +324,22 @@ func (handlerDirectoryResource) newDiffedDirectory(rd *schema.ResourceData) (old
 	old.path, new.path = cast.ToString(o), cast.ToString(n)
 
 	o, n = rd.GetChange(attrDirectoryOwner)
-	old.permission.owner, new.permission.owner = cast.ToUint16(o), cast.ToUint16(n)
+	old.permission.owner, new.permission.owner = cast.ToUint32(o), cast.ToUint32(n)
 
 	o, n = rd.GetChange(attrDirectoryGroup)
-	old.permission.group, new.permission.group = cast.ToUint16(o), cast.ToUint16(n)
+	old.permission.group, new.permission.group = cast.ToUint32(o), cast.ToUint32(n)
+
+	o, n = rd.GetChange(attrDirectoryOwnerName)
+	old.permission.ownerName, new.permission.ownerName = cast.ToString(o), cast.ToString(n)
+	if !configured(rd.GetRawConfig(), attrDirectoryOwnerName) {
+		old.permission.ownerName, new.permission.ownerName = "", "" // compare by id
+	}
+
+	o, n = rd.GetChange(attrDirectoryGroupName)
+	old.permission.groupName, new.permission.groupName = cast.ToString(o), cast.ToString(n)
+	if !configured(rd.GetRawConfig(), attrDirectoryGroupName) {
+		old.permission.groupName, new.permission.groupName = "", "" // compare by id
+	}
 
 	o, n = rd.GetChange(attrDirectoryMode)
 	old.permission.mode, new.permission.mode = cast.ToString(o), cast.ToString(n)
@@ -108,7 +348,82 @@ func (handlerDirectoryResource) newDiffedDirectory(rd *schema.ResourceData) (old
 	old.overwrite, new.overwrite = cast.ToBool(o), cast.ToBool(n)
 
 	o, n = rd.GetChange(attrDirectoryRecyclePath)
-	old.recyclePath, new.recyclePath = cast.ToString(o), cast.ToString(n)
+	old.recycle.path, new.recycle.path = cast.ToString(o), cast.ToString(n)
+
+	o, n = rd.GetChange(attrDirectoryRecycleRetention)
+	old.recycle.retention, new.recycle.retention = cast.ToString(o), cast.ToString(n)
+
+	o, n = rd.GetChange(attrDirectoryRecycleMaxItems)
+	old.recycle.maxItems, new.recycle.maxItems = cast.ToInt(o), cast.ToInt(n)
+
+	o, n = rd.GetChange(attrDirectoryDeleteStrategy)
+	old.deleteStrategy, new.deleteStrategy = cast.ToString(o), cast.ToString(n)
+
+	o, n = rd.GetChange(attrDirectoryRestoreFromRecycle)
+	old.restore, new.restore = cast.ToBool(o), cast.ToBool(n)
+
+	o, n = rd.GetChange(attrDirectoryRecursiveOwner)
+	old.recursive.owner, new.recursive.owner = cast.ToBool(o), cast.ToBool(n)
+
+	o, n = rd.GetChange(attrDirectoryRecursiveGroup)
+	old.recursive.group, new.recursive.group = cast.ToBool(o), cast.ToBool(n)
+
+	o, n = rd.GetChange(attrDirectoryFileMode)
+	old.recursive.fileMode, new.recursive.fileMode = normalizeMode(cast.ToString(o)), normalizeMode(cast.ToString(n))
+
+	o, n = rd.GetChange(attrDirectoryDirMode)
+	old.recursive.dirMode, new.recursive.dirMode = normalizeMode(cast.ToString(o)), normalizeMode(cast.ToString(n))
+
+	o, n = rd.GetChange(attrDirectorySource)
+	old.source, new.source = cast.ToString(o), cast.ToString(n)
+
+	o, n = rd.GetChange(attrDirectorySourcePurge)
+	old.sourcePurge, new.sourcePurge = cast.ToBool(o), cast.ToBool(n)
+
+	o, n = rd.GetChange(attrDirectorySourceManifest)
+	old.sourceManifest, new.sourceManifest = cast.ToStringMapString(o), cast.ToStringMapString(n)
+
+	o, n = rd.GetChange(attrDirectoryOnCreate)
+	old.hook.onCreate, new.hook.onCreate = cast.ToString(o), cast.ToString(n)
+
+	o, n = rd.GetChange(attrDirectoryOnChange)
+	old.hook.onChange, new.hook.onChange = cast.ToString(o), cast.ToString(n)
+
+	o, n = rd.GetChange(attrDirectoryOnDestroy)
+	old.hook.onDestroy, new.hook.onDestroy = cast.ToString(o), cast.ToString(n)
+
+	o, n = rd.GetChange(attrDirectoryHookWorkdir)
+	old.hook.workdir, new.hook.workdir = cast.ToString(o), cast.ToString(n)
+
+	o, n = rd.GetChange(attrDirectoryHookEnvironment)
+	old.hook.env, new.hook.env = cast.ToStringMapString(o), cast.ToStringMapString(n)
+
+	o, n = rd.GetChange(attrDirectoryBackup)
+	old.backup, new.backup = cast.ToBool(o), cast.ToBool(n)
+
+	o, n = rd.GetChange(attrDirectoryBackupPath)
+	old.backupPath, new.backupPath = cast.ToString(o), cast.ToString(n)
+
+	old.backupLocation = cast.ToString(rd.Get(attrDirectoryBackupLocation))
+	new.backupLocation = old.backupLocation
+	old.backupLocations = cast.ToStringSlice(rd.Get(attrDirectoryBackupLocations))
+	new.backupLocations = old.backupLocations
+
+	o, n = rd.GetChange(attrDirectorySelinuxContext)
+	old.attributes.selinuxContext, new.attributes.selinuxContext = cast.ToString(o), cast.ToString(n)
+
+	o, n = rd.GetChange(attrDirectoryACL)
+	old.attributes.acl, new.attributes.acl = aclFromSet(o), aclFromSet(n)
+
+	o, n = rd.GetChange(attrDirectoryXattrs)
+	old.attributes.xattrs, new.attributes.xattrs = cast.ToStringMapString(o), cast.ToStringMapString(n)
+
+	old.permission = unmanagedPermission(rd.GetRawConfig(), old.permission,
+		attrDirectoryOwner, attrDirectoryOwnerName, attrDirectoryGroup, attrDirectoryGroupName, attrDirectoryMode)
+	new.permission = unmanagedPermission(rd.GetRawConfig(), new.permission,
+		attrDirectoryOwner, attrDirectoryOwnerName, attrDirectoryGroup, attrDirectoryGroupName, attrDirectoryMode)
+	old.attributes = unmanagedAttributes(rd.GetRawConfig(), old.attributes, attrDirectorySelinuxContext, attrDirectoryACL, attrDirectoryXattrs)
+	new.attributes = unmanagedAttributes(rd.GetRawConfig(), new.attributes, attrDirectorySelinuxContext, attrDirectoryACL, attrDirectoryXattrs)
 	return
 }
 
@@ -121,21 +436,108 @@ func (handlerDirectoryResource) updateResourceData(d *directory, rd *schema.Reso
 	if err = rd.Set(attrDirectoryPath, d.path); err != nil {
 		return
 	}
-	if err = rd.Set(attrDirectoryOwner, d.permission.owner); err != nil {
-		return
+	if d.permission.owner != idUnmanaged {
+		if err = rd.Set(attrDirectoryOwner, d.permission.owner); err != nil {
+			return
+		}
 	}
-	if err = rd.Set(attrDirectoryGroup, d.permission.group); err != nil {
+	if d.permission.group != idUnmanaged {
+		if err = rd.Set(attrDirectoryGroup, d.permission.group); err != nil {
+			return
+		}
+	}
+	if err = rd.Set(attrDirectoryOwnerName, d.permission.ownerName); err != nil {
 		return
 	}
-	if err = rd.Set(attrDirectoryMode, d.permission.mode); err != nil {
+	if err = rd.Set(attrDirectoryGroupName, d.permission.groupName); err != nil {
 		return
 	}
+	if d.permission.mode != "" {
+		if err = rd.Set(attrDirectoryMode, d.permission.mode); err != nil {
+			return
+		}
+	}
 	if err = rd.Set(attrDirectoryOverwrite, d.overwrite); err != nil {
 		return
 	}
-	if err = rd.Set(attrDirectoryRecyclePath, d.recyclePath); err != nil {
+	if err = rd.Set(attrDirectoryRecyclePath, d.recycle.path); err != nil {
+		return
+	}
+	if err = rd.Set(attrDirectoryRecycleRetention, d.recycle.retention); err != nil {
+		return
+	}
+	if err = rd.Set(attrDirectoryRecycleMaxItems, d.recycle.maxItems); err != nil {
+		return
+	}
+	if err = rd.Set(attrDirectoryDeleteStrategy, d.deleteStrategy); err != nil {
+		return
+	}
+	if err = rd.Set(attrDirectoryRestoreFromRecycle, d.restore); err != nil {
+		return
+	}
+	if err = rd.Set(attrDirectoryRecursiveOwner, d.recursive.owner); err != nil {
+		return
+	}
+	if err = rd.Set(attrDirectoryRecursiveGroup, d.recursive.group); err != nil {
+		return
+	}
+	if err = rd.Set(attrDirectoryFileMode, d.recursive.fileMode); err != nil {
+		return
+	}
+	if err = rd.Set(attrDirectoryDirMode, d.recursive.dirMode); err != nil {
+		return
+	}
+	if err = rd.Set(attrDirectorySource, d.source); err != nil {
+		return
+	}
+	if err = rd.Set(attrDirectorySourcePurge, d.sourcePurge); err != nil {
+		return
+	}
+	if err = rd.Set(attrDirectorySourceManifest, d.sourceManifest); err != nil {
+		return
+	}
+	if err = rd.Set(attrDirectoryOnCreate, d.hook.onCreate); err != nil {
+		return
+	}
+	if err = rd.Set(attrDirectoryOnChange, d.hook.onChange); err != nil {
 		return
 	}
+	if err = rd.Set(attrDirectoryOnDestroy, d.hook.onDestroy); err != nil {
+		return
+	}
+	if err = rd.Set(attrDirectoryHookWorkdir, d.hook.workdir); err != nil {
+		return
+	}
+	if err = rd.Set(attrDirectoryHookEnvironment, d.hook.env); err != nil {
+		return
+	}
+	if err = rd.Set(attrDirectoryBackup, d.backup); err != nil {
+		return
+	}
+	if err = rd.Set(attrDirectoryBackupPath, d.backupPath); err != nil {
+		return
+	}
+	if err = rd.Set(attrDirectoryBackupLocation, d.backupLocation); err != nil {
+		return
+	}
+	if err = rd.Set(attrDirectoryBackupLocations, d.backupLocations); err != nil {
+		return
+	}
+	if d.attributes.selinuxContext != "" {
+		if err = rd.Set(attrDirectorySelinuxContext, d.attributes.selinuxContext); err != nil {
+			return
+		}
+	}
+	if d.attributes.acl != nil {
+		if err = rd.Set(attrDirectoryACL, d.attributes.acl); err != nil {
+			return
+		}
+	}
+	if d.attributes.xattrs != nil {
+		if err = rd.Set(attrDirectoryXattrs, d.attributes.xattrs); err != nil {
+			return
+		}
+	}
 	return
 }
 
@@ -146,12 +548,37 @@ func (h handlerDirectoryResource) Read(ctx context.Context, rd *schema.ResourceD
 	}
 
 	d, err := l.readDirectory(ctx, cast.ToString(rd.Get(attrDirectoryPath)))
-	if err != nil && !errors.Is(err, errPathNotExist) {
+	if errors.Is(err, errPathNotExist) {
+		rd.SetId("")
+		return
+	}
+	if err != nil {
 		return diag.FromErr(err)
 	}
 
+	d.attributes.acl = aclAsConfigured(rd.Get(attrDirectoryACL), d.attributes.acl)
 	d.overwrite = cast.ToBool(rd.Get(attrDirectoryOverwrite))
-	d.recyclePath = cast.ToString(rd.Get(attrDirectoryRecyclePath))
+	d.recycle = newRecycleBin(rd, attrDirectoryRecyclePath, attrDirectoryRecycleRetention, attrDirectoryRecycleMaxItems)
+	d.deleteStrategy = cast.ToString(rd.Get(attrDirectoryDeleteStrategy))
+	d.restore = cast.ToBool(rd.Get(attrDirectoryRestoreFromRecycle))
+	d.hook = h.newHook(rd)
+	d.recursive, err = l.readDirectoryRecursive(ctx, d.path, d.permission, h.newDirectory(rd).recursive)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	d.source = cast.ToString(rd.Get(attrDirectorySource))
+	d.sourcePurge = cast.ToBool(rd.Get(attrDirectorySourcePurge))
+	if d.source != "" {
+		d.sourceManifest, err = l.readDirectorySourceManifest(ctx, d.path,
+			cast.ToStringMapString(rd.Get(attrDirectorySourceManifest)), d.sourcePurge)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+	}
+	d.backup = cast.ToBool(rd.Get(attrDirectoryBackup))
+	d.backupPath = cast.ToString(rd.Get(attrDirectoryBackupPath))
+	d.backupLocation = cast.ToString(rd.Get(attrDirectoryBackupLocation))
+	d.backupLocations = cast.ToStringSlice(rd.Get(attrDirectoryBackupLocations))
 	if err = h.updateResourceData(d, rd); err != nil {
 		diag.FromErr(err)
 	}
@@ -165,7 +592,14 @@ func (h handlerDirectoryResource) Create(ctx context.Context, rd *schema.Resourc
 	}
 
 	d := h.newDirectory(rd)
-	if err := l.createDirectory(ctx, d); err != nil {
+	errCreate := l.createDirectory(ctx, d)
+	if errCreate != nil && !errors.Is(errCreate, errHook) {
+		return diag.FromErr(errCreate)
+	}
+	if err := rd.Set(attrDirectoryBackupLocation, d.backupLocation); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := rd.Set(attrDirectoryBackupLocations, d.backupLocations); err != nil {
 		return diag.FromErr(err)
 	}
 
@@ -175,6 +609,9 @@ func (h handlerDirectoryResource) Create(ctx context.Context, rd *schema.Resourc
 	}
 
 	rd.SetId(id.String())
+	if errCreate != nil { // the directory has been written, so let the failed hook taint it
+		return append(diag.FromErr(errCreate), h.Read(ctx, rd, meta)...)
+	}
 	return h.Read(ctx, rd, meta)
 }
 
@@ -190,6 +627,12 @@ func (h handlerDirectoryResource) Update(ctx context.Context, rd *schema.Resourc
 		_ = h.updateResourceData(old, rd) // WARN: see https://github.com/hashicorp/terraform-plugin-sdk/issues/476
 		return diag.FromErr(err)
 	}
+	if err = rd.Set(attrDirectoryBackupLocation, new.backupLocation); err != nil {
+		return diag.FromErr(err)
+	}
+	if err = rd.Set(attrDirectoryBackupLocations, new.backupLocations); err != nil {
+		return diag.FromErr(err)
+	}
 
 	return h.Read(ctx, rd, meta)
 }
@@ -206,6 +649,32 @@ func (h handlerDirectoryResource) Delete(ctx context.Context, rd *schema.Resourc
 	return
 }
 
+func (h handlerDirectoryResource) CustomizeDiff(ctx context.Context, rd *schema.ResourceDiff, meta interface{}) (err error) {
+	if err = validateDeleteStrategy(rd, attrDirectoryDeleteStrategy, attrDirectoryRecyclePath); err != nil {
+		return
+	}
+	if !rd.NewValueKnown(attrDirectorySource) {
+		return rd.SetNewComputed(attrDirectorySourceManifest)
+	}
+	manifest := map[string]string{}
+	if source := cast.ToString(rd.Get(attrDirectorySource)); source != "" {
+		if manifest, _, err = localSourceManifest(source); err != nil {
+			return
+		}
+	}
+	if reflect.DeepEqual(manifest, cast.ToStringMapString(rd.Get(attrDirectorySourceManifest))) {
+		return
+	}
+	return rd.SetNew(attrDirectorySourceManifest, manifest)
+}
+
+func (h handlerDirectoryResource) Import(ctx context.Context, rd *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	if err := importPath(meta.(*linuxPool), rd, schemaDirectoryResource, attrDirectoryProviderOverride, attrDirectoryPath); err != nil {
+		return nil, err
+	}
+	return []*schema.ResourceData{rd}, nil
+}
+
 func directoryResource() *schema.Resource {
 	var hdr handlerDirectoryResource
 	return &schema.Resource{
@@ -214,5 +683,9 @@ func directoryResource() *schema.Resource {
 		ReadContext:   hdr.Read,
 		UpdateContext: hdr.Update,
 		DeleteContext: hdr.Delete,
+		CustomizeDiff: hdr.CustomizeDiff,
+		Importer: &schema.ResourceImporter{
+			StateContext: hdr.Import,
+		},
 	}
 }
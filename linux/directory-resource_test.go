@@ -1,10 +1,14 @@
 package linux
 
 import (
+	"fmt"
+	"os"
+	"path/filepath"
 	"regexp"
 	"testing"
 
 	"github.com/MakeNowJust/heredoc"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/stretchr/testify/require"
 )
@@ -22,6 +26,10 @@ func TestAccLinuxDirectoryBasic(t *testing.T) {
 		Directory: tNewTFMapDirectory(),
 		Extra:     tfmap{"path_previous": conf1.Directory[attrDirectoryPath]},
 	}
+	conf4 := conf3.Copy(func(tc *tfConf) {
+		tc.Directory.With(attrDirectoryMode, `"1777"`)
+		tc.Extra = tfmap{}
+	})
 
 	resource.Test(t, resource.TestCase{
 		ExternalProviders: map[string]resource.ExternalProvider{"null": {}},
@@ -36,6 +44,9 @@ func TestAccLinuxDirectoryBasic(t *testing.T) {
 			{
 				Config: testAccLinuxDirectoryBasicConfig(t, conf3),
 			},
+			{
+				Config: testAccLinuxDirectoryBasicConfig(t, conf4),
+			},
 		},
 	})
 }
@@ -336,3 +347,221 @@ func testAccLinuxDirectoryRecyclePathConfig(t *testing.T, conf tfConf) (s string
 	require.NoError(t, err, "compile template failed")
 	return
 }
+
+func TestAccLinuxDirectoryHook(t *testing.T) {
+	marker := fmt.Sprintf(`"/tmp/linux/%s.hook"`, acctest.RandString(16))
+	conf1 := tfConf{
+		Provider: testAccProvider,
+		Directory: tNewTFMapDirectory().
+			With(attrDirectoryOnCreate, `"echo create >> $MARKER"`).
+			With(attrDirectoryOnChange, `"echo change >> $MARKER"`).
+			With(attrDirectoryOnDestroy, `"echo destroy >> $MARKER"`),
+		Extra: tfmap{"marker": marker, "expected": `"create"`},
+	}
+	conf2 := conf1.Copy(func(tc *tfConf) {
+		tc.Directory.With(attrDirectoryMode, `"700"`)
+		tc.Extra.With("expected", `"create change"`)
+	})
+	conf3 := conf2.Copy(func(tc *tfConf) {
+		tc.Directory.With(attrDirectoryOverwrite, "true")
+	})
+
+	resource.Test(t, resource.TestCase{
+		ExternalProviders: map[string]resource.ExternalProvider{"null": {}},
+		PreCheck:          testAccPreCheckConnection(t),
+		Providers:         testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccLinuxDirectoryHookConfig(t, conf1),
+			},
+			{
+				Config: testAccLinuxDirectoryHookConfig(t, conf2),
+			},
+			{
+				Config: testAccLinuxDirectoryHookConfig(t, conf3),
+			},
+		},
+	})
+}
+
+func testAccLinuxDirectoryHookConfig(t *testing.T, conf tfConf) (s string) {
+	s, err := conf.compile(tHookConfig("linux_directory.directory", "{{- .Directory.Serialize | nindent 4 }}", attrDirectoryMode, attrDirectoryOverwrite))
+	t.Log(s)
+	require.NoError(t, err, "compile template failed")
+	return
+}
+
+func TestAccLinuxDirectorySource(t *testing.T) {
+	source := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(source, "sub"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(source, "a.txt"), []byte("a"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(source, "sub", "b.txt"), []byte("b"), 0644))
+
+	conf1 := tfConf{
+		Provider: testAccProvider,
+		Directory: tfmap{
+			attrDirectoryPath:   fmt.Sprintf(`"/tmp/linux/%s"`, acctest.RandString(16)),
+			attrDirectoryOwner:  "1000",
+			attrDirectoryMode:   `"750"`,
+			attrDirectorySource: fmt.Sprintf("%q", source),
+		},
+		Extra: tfmap{"present": `"a.txt sub/b.txt"`, "absent": `"c.txt"`},
+	}
+	conf2 := conf1.Copy(func(tc *tfConf) {
+		tc.Directory.With(attrDirectorySourcePurge, "true")
+		tc.Extra.With("present", `"sub/b.txt c.txt"`)
+		tc.Extra.With("absent", `"a.txt"`)
+	})
+
+	resource.Test(t, resource.TestCase{
+		ExternalProviders: map[string]resource.ExternalProvider{"null": {}},
+		PreCheck:          testAccPreCheckConnection(t),
+		Providers:         testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccLinuxDirectorySourceConfig(t, conf1),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("linux_directory.directory", "source_manifest.%", "2"),
+					resource.TestCheckResourceAttr("linux_directory.directory", "source_manifest.a.txt",
+						"ca978112ca1bbdcafac231b39a23dc4da786eff8147c4e72b9807785afee48bb"),
+				),
+			},
+			{
+				PreConfig: func() {
+					require.NoError(t, os.Remove(filepath.Join(source, "a.txt")))
+					require.NoError(t, os.WriteFile(filepath.Join(source, "c.txt"), []byte("c"), 0644))
+				},
+				Config: testAccLinuxDirectorySourceConfig(t, conf2),
+			},
+		},
+	})
+}
+
+func testAccLinuxDirectorySourceConfig(t *testing.T, conf tfConf) (s string) {
+	tf := heredoc.Doc(`
+		provider "linux" {
+			alias = "test"
+		    {{- .Provider.Serialize | nindent 4 }}
+		}
+
+		resource "linux_directory" "directory" {
+			provider = linux.test
+
+		    {{- .Directory.Serialize | nindent 4 }}
+		}
+
+		resource "null_resource" "create_validator" {
+		    triggers = {
+		        path = linux_directory.directory.path
+		        manifest = jsonencode(linux_directory.directory.source_manifest)
+		    }
+		    connection {
+		        type = "ssh"
+		        {{- .Provider.Serialize | nindent 8 }}
+		    }
+		    provisioner "remote-exec" {
+		        inline = [
+		            <<-EOF
+		                cd '${self.triggers["path"]}' || exit 100
+		                for f in {{ .Extra.present }}; do [ -f "$f" ] || exit 101; done
+		                for f in {{ .Extra.absent }}; do [ ! -e "$f" ] || exit 102; done
+		                [ "$( stat -c %u sub/b.txt )" == "{{ .Directory.owner }}" ] || exit 103
+		                [ "$( stat -c %u sub )" == "{{ .Directory.owner }}" ] || exit 104
+		                [ "$( stat -c %a sub/b.txt )" == {{ .Directory.mode }} ] || exit 105
+		                [ "$( stat -c %a sub )" == {{ .Directory.mode }} ] || exit 106
+		            EOF
+		        ]
+		    }
+		}
+	`)
+	s, err := conf.compile(tf)
+	t.Log(s)
+	require.NoError(t, err, "compile template failed")
+	return
+}
+
+func TestAccLinuxDirectoryRecursive(t *testing.T) {
+	conf := tfConf{
+		Provider: testAccProvider,
+		Directory: tfmap{
+			attrDirectoryPath:           fmt.Sprintf(`"/tmp/linux/%s"`, acctest.RandString(16)),
+			attrDirectoryOwner:          "1000",
+			attrDirectoryGroup:          "1000",
+			attrDirectoryRecursiveOwner: "true",
+			attrDirectoryRecursiveGroup: "true",
+			attrDirectoryFileMode:       `"640"`,
+			attrDirectoryDirMode:        `"750"`,
+		},
+	}
+
+	resource.Test(t, resource.TestCase{
+		ExternalProviders: map[string]resource.ExternalProvider{"null": {}},
+		PreCheck:          testAccPreCheckConnection(t),
+		Providers:         testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccLinuxDirectoryRecursiveConfig(t, conf),
+			},
+		},
+	})
+}
+
+func testAccLinuxDirectoryRecursiveConfig(t *testing.T, conf tfConf) (s string) {
+	tf := heredoc.Doc(`
+		provider "linux" {
+			alias = "test"
+		    {{- .Provider.Serialize | nindent 4 }}
+		}
+
+		resource "null_resource" "tree" {
+		    connection {
+		        type = "ssh"
+		        {{- .Provider.Serialize | nindent 8 }}
+		    }
+		    provisioner "remote-exec" {
+		        inline = [
+		            <<-EOF
+		                mkdir -p {{ .Directory.path }}/sub
+		                printf 'a' > {{ .Directory.path }}/a
+		                printf 'b' > {{ .Directory.path }}/sub/b
+		                chmod 600 {{ .Directory.path }}/a {{ .Directory.path }}/sub/b
+		                chmod 700 {{ .Directory.path }}/sub
+		            EOF
+		        ]
+		    }
+		}
+
+		resource "linux_directory" "directory" {
+			provider = linux.test
+		    depends_on = [ null_resource.tree ]
+
+		    {{- .Directory.Serialize | nindent 4 }}
+		    overwrite = true
+		}
+
+		resource "null_resource" "create_validator" {
+		    triggers = {
+		        path = linux_directory.directory.path
+		    }
+		    connection {
+		        type = "ssh"
+		        {{- .Provider.Serialize | nindent 8 }}
+		    }
+		    provisioner "remote-exec" {
+		        inline = [
+		            <<-EOF
+		                cd '${self.triggers["path"]}' || exit 100
+		                [ "$( stat -c '%u:%g' sub/b )" == "1000:1000" ] || exit 101
+		                [ "$( stat -c %a a )" == "640" ] || exit 102
+		                [ "$( stat -c %a sub/b )" == "640" ] || exit 103
+		                [ "$( stat -c %a sub )" == "750" ] || exit 104
+		            EOF
+		        ]
+		    }
+		}
+	`)
+	s, err := conf.compile(tf)
+	t.Log(s)
+	require.NoError(t, err, "compile template failed")
+	return
+}
@@ -0,0 +1,146 @@
+package linux
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"al.essio.dev/pkg/shellescape"
+	"github.com/hashicorp/terraform/communicator/remote"
+)
+
+// localSourceManifest returns SHA256 checksums of files under the local directory src keyed by their
+// slash separated relative path, along with the relative paths of its sub directories.
+// Symlinks to files are followed, as they are when uploaded.
+func localSourceManifest(src string) (files map[string]string, dirs map[string]bool, err error) {
+	files, dirs = map[string]string{}, map[string]bool{}
+	err = filepath.WalkDir(src, func(path string, de fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil || rel == "." {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		fi, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		switch {
+		case de.IsDir():
+			dirs[rel] = true
+			return nil
+		case fi.IsDir():
+			return fmt.Errorf("symlink to directory '%s' is not supported", path)
+		case !fi.Mode().IsRegular():
+			return nil
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		h := sha256.New()
+		if _, err = io.Copy(h, f); err != nil {
+			return err
+		}
+		files[rel] = hex.EncodeToString(h.Sum(nil))
+		return nil
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("while reading source directory: %w", err)
+	}
+	return
+}
+
+// syncDirectorySource uploads the local source into the directory, removes remote entries that don't exist
+// locally when purge is enabled, and applies the directory owner, group and mode recursively.
+func (l *linux) syncDirectorySource(ctx context.Context, d *directory) (err error) {
+	files, dirs, err := localSourceManifest(d.source)
+	if err != nil {
+		return
+	}
+	if err = l.uploadDir(ctx, d.path, d.source); err != nil {
+		return fmt.Errorf("while uploading source directory: %w", err)
+	}
+	if d.sourcePurge {
+		if err = l.purgeDirectory(ctx, d.path, files, dirs); err != nil {
+			return
+		}
+	}
+
+	r, err := l.resolvePermission(ctx, d.permission)
+	if err != nil {
+		return
+	}
+	var cmds []string
+	if spec := r.chownSpec(); spec != "" {
+		cmds = append(cmds, shellescape.QuoteCommand([]string{"chown", "-R", spec, d.path}))
+	}
+	if r.mode != "" {
+		cmds = append(cmds, shellescape.QuoteCommand([]string{"find", d.path, "-mindepth", "1", "-type", "d", "-exec", "chmod", r.mode, "{}", "+"}))
+		cmds = append(cmds, shellescape.QuoteCommand([]string{"find", d.path, "-mindepth", "1", "-type", "f", "-exec", "chmod", r.mode, "{}", "+"}))
+	}
+	if len(cmds) > 0 {
+		if err = l.exec(ctx, &remote.Cmd{Command: fmt.Sprintf(`{ %s ;}`, strings.Join(cmds, " && "))}); err != nil {
+			return
+		}
+	}
+	d.sourceManifest = files
+	return
+}
+
+func (l *linux) purgeDirectory(ctx context.Context, path string, files map[string]string, dirs map[string]bool) (err error) {
+	entries, err := l.listDirectory(ctx, path, "", 0)
+	if err != nil {
+		return
+	}
+	var stale []string
+	for _, e := range entries {
+		keep := dirs[e.name]
+		if e.kind != directoryEntryDirectory {
+			_, keep = files[e.name]
+		}
+		if !keep {
+			stale = append(stale, filepath.Join(path, e.name))
+		}
+	}
+	if len(stale) == 0 {
+		return
+	}
+	sort.Strings(stale)
+	cmd := shellescape.QuoteCommand(append([]string{"rm", "-rf", "--"}, stale...))
+	if err = l.exec(ctx, &remote.Cmd{Command: cmd}); err != nil {
+		return fmt.Errorf("while purging directory: %w", err)
+	}
+	return
+}
+
+// readDirectorySourceManifest returns checksums of remote files that were uploaded from the source.
+// All files are returned when purge is enabled, so that unmanaged files are reported as drift.
+func (l *linux) readDirectorySourceManifest(ctx context.Context, path string, managed map[string]string, purge bool) (m map[string]string, err error) {
+	entries, err := l.listDirectory(ctx, path, "", 0)
+	if err != nil {
+		return
+	}
+	m = map[string]string{}
+	for _, e := range entries {
+		if e.kind == directoryEntryDirectory {
+			continue
+		}
+		if _, ok := managed[e.name]; ok || purge {
+			m[e.name] = e.sha256
+		}
+	}
+	return
+}
@@ -1,8 +1,14 @@
 package linux
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
 
 	"al.essio.dev/pkg/shellescape"
 	"github.com/hashicorp/terraform/communicator/remote"
@@ -11,9 +17,42 @@ import (
 type directory struct {
 	path       string
 	permission permission
+	attributes attributes
 
-	overwrite   bool
-	recyclePath string
+	overwrite      bool
+	recycle        recycleBin
+	restore        bool
+	deleteStrategy string
+
+	recursive directoryRecursive
+
+	source         string
+	sourcePurge    bool
+	sourceManifest map[string]string
+
+	backup          bool
+	backupPath      string
+	backupLocation  string
+	backupLocations []string
+
+	hook hook
+}
+
+// directoryRecursive holds permission enforced on every descendant of a directory.
+// Empty fileMode or dirMode leaves the respective mode untouched.
+type directoryRecursive struct {
+	owner    bool
+	group    bool
+	fileMode string
+	dirMode  string
+}
+
+func (r directoryRecursive) enabled() bool {
+	return r.owner || r.group || r.fileMode != "" || r.dirMode != ""
+}
+
+func (d *directory) backupEnabled() bool {
+	return d.backup || d.backupPath != ""
 }
 
 func (l *linux) readDirectory(ctx context.Context, path string) (d *directory, err error) {
@@ -21,7 +60,11 @@ func (l *linux) readDirectory(ctx context.Context, path string) (d *directory, e
 	if err != nil {
 		return
 	}
-	d = &directory{path: path, permission: perm}
+	attrs, err := l.getAttributes(ctx, path)
+	if err != nil {
+		return
+	}
+	d = &directory{path: path, permission: perm, attributes: attrs}
 	return
 }
 
@@ -29,7 +72,25 @@ func (l *linux) createDirectory(ctx context.Context, d *directory) (err error) {
 	if d == nil {
 		return errNil
 	}
+	if d.restore {
+		restored, err := l.restoreFromRecycleBin(ctx, d.path, d.recycle)
+		if err != nil {
+			return err
+		}
+		d.overwrite = d.overwrite || restored // the restored path is expected to exist
+	}
+	if d.overwrite && d.backupEnabled() {
+		if err = l.backupDirectory(ctx, d, d.path); err != nil {
+			return
+		}
+	}
+	if err = l.writeDirectory(ctx, d); err != nil {
+		return
+	}
+	return l.runHook(ctx, d.hook, d.hook.onCreate)
+}
 
+func (l *linux) writeDirectory(ctx context.Context, d *directory) (err error) {
 	if !d.overwrite {
 		if err = l.reservePath(ctx, d.path); err != nil {
 			return
@@ -41,14 +102,41 @@ func (l *linux) createDirectory(ctx context.Context, d *directory) (err error) {
 		return
 	}
 
-	return l.setPermission(ctx, d.path, d.permission)
+	if err = l.setPermission(ctx, d.path, &d.permission); err != nil {
+		return
+	}
+	if d.source != "" {
+		if err = l.syncDirectorySource(ctx, d); err != nil {
+			return
+		}
+	}
+	if d.recursive.enabled() {
+		if err = l.setDirectoryRecursive(ctx, d.path, d.recursive); err != nil {
+			return
+		}
+	}
+	return l.setAttributes(ctx, d.path, d.attributes)
 }
 
 func (l *linux) deleteDirectory(ctx context.Context, f *directory) (err error) {
 	if f == nil {
 		return
 	}
-	return l.remove(ctx, f.path, f.recyclePath)
+	if err = l.remove(ctx, f.path, newDeleteStrategy(f.deleteStrategy, f.recycle)); err != nil {
+		return
+	}
+	return l.runHook(ctx, f.hook, f.hook.onDestroy)
+}
+
+// backupDirectory backs up the existing paths, recording their locations in d. The previous locations are kept
+// when nothing is backed up.
+func (l *linux) backupDirectory(ctx context.Context, d *directory, paths ...string) (err error) {
+	locations, err := l.backups(ctx, d.backupPath, paths...)
+	if err != nil || len(locations) == 0 {
+		return
+	}
+	d.backupLocation, d.backupLocations = locations[0], locations
+	return
 }
 
 func (l *linux) updateDirectory(ctx context.Context, old, new *directory) (err error) {
@@ -58,6 +146,19 @@ func (l *linux) updateDirectory(ctx context.Context, old, new *directory) (err e
 	if new == nil {
 		return l.deleteDirectory(ctx, old)
 	}
+	if old.path == new.path && old.permission == new.permission && old.attributes.equal(new.attributes) && old.recursive == new.recursive &&
+		old.source == new.source && old.sourcePurge == new.sourcePurge && reflect.DeepEqual(old.sourceManifest, new.sourceManifest) {
+		return // nothing changed on remote
+	}
+	if new.backupEnabled() {
+		paths := []string{old.path}
+		if old.path != new.path && new.overwrite {
+			paths = append(paths, new.path) // about to be replaced
+		}
+		if err = l.backupDirectory(ctx, new, paths...); err != nil {
+			return
+		}
+	}
 	if old.path != new.path {
 		if !new.overwrite {
 			if err = l.reservePath(ctx, new.path); err != nil {
@@ -84,5 +185,174 @@ func (l *linux) updateDirectory(ctx context.Context, old, new *directory) (err e
 	d := &directory{}
 	*d = *new
 	d.overwrite = true
-	return l.createDirectory(ctx, d)
+	if err = l.writeDirectory(ctx, d); err != nil {
+		return
+	}
+	new.permission, new.sourceManifest = d.permission, d.sourceManifest
+	return l.runHook(ctx, d.hook, d.hook.onChange)
+}
+
+// setDirectoryRecursive applies the owner and group of the directory, and the file and directory modes,
+// to all of its descendants. Symlinks are chowned themselves and never followed.
+func (l *linux) setDirectoryRecursive(ctx context.Context, path string, r directoryRecursive) (err error) {
+	p, err := l.getPermission(ctx, path)
+	if err != nil {
+		return
+	}
+	if !r.owner {
+		p.owner = idUnmanaged
+	}
+	if !r.group {
+		p.group = idUnmanaged
+	}
+
+	var cmds []string
+	if spec := p.chownSpec(); spec != "" {
+		cmds = append(cmds, shellescape.QuoteCommand([]string{"chown", "-R", "-h", spec, path}))
+	}
+	if r.fileMode != "" {
+		cmds = append(cmds, shellescape.QuoteCommand([]string{"find", path, "-mindepth", "1", "-type", "f", "-exec", "chmod", r.fileMode, "{}", "+"}))
+	}
+	if r.dirMode != "" {
+		cmds = append(cmds, shellescape.QuoteCommand([]string{"find", path, "-mindepth", "1", "-type", "d", "-exec", "chmod", r.dirMode, "{}", "+"}))
+	}
+	if len(cmds) == 0 {
+		return
+	}
+	return l.exec(ctx, &remote.Cmd{Command: fmt.Sprintf(`{ %s ;}`, strings.Join(cmds, " && "))})
+}
+
+// readDirectoryRecursive returns r as it is observed on remote: owner or group is false when any descendant
+// deviates from the directory, and fileMode or dirMode is the mode of the first deviating descendant.
+// Each check stops at the first deviation, so the cost doesn't depend on the number of conforming descendants.
+func (l *linux) readDirectoryRecursive(ctx context.Context, path string, p permission, r directoryRecursive) (o directoryRecursive, err error) {
+	o = r
+	checks := map[*string][]string{}
+	var owner, group string
+	if r.owner {
+		checks[&owner] = []string{"!", "-uid", strconv.FormatUint(uint64(p.owner), 10), "-printf", "x"}
+	}
+	if r.group {
+		checks[&group] = []string{"!", "-gid", strconv.FormatUint(uint64(p.group), 10), "-printf", "x"}
+	}
+	if r.fileMode != "" {
+		checks[&o.fileMode] = []string{"-type", "f", "!", "-perm", r.fileMode, "-printf", "%m"}
+	}
+	if r.dirMode != "" {
+		perm := []string{"-perm", r.dirMode}
+		if m, _ := strconv.ParseUint(r.dirMode, 8, 32); m&07000 == 0 {
+			// chmod preserves set-user-ID and set-group-ID of directories
+			for _, s := range []uint64{02000, 04000, 06000} {
+				perm = append(perm, "-o", "-perm", fmt.Sprintf("%o", m|s))
+			}
+		}
+		checks[&o.dirMode] = append(append([]string{"-type", "d", "!", "("}, perm...), ")", "-printf", "%m")
+	}
+
+	for v, args := range checks {
+		stdout := new(bytes.Buffer)
+		args = append(append([]string{"find", path, "-mindepth", "1"}, args...), "-quit")
+		if err = l.exec(ctx, &remote.Cmd{Command: shellescape.QuoteCommand(args), Stdout: stdout}); err != nil {
+			return
+		}
+		if out := strings.TrimSpace(stdout.String()); out != "" {
+			*v = out
+		}
+	}
+	o.owner = r.owner && owner == ""
+	o.group = r.group && group == ""
+	return
+}
+
+type directoryEntry struct {
+	name   string // relative to the listed directory
+	kind   string
+	size   int64
+	mode   string
+	owner  uint32
+	group  uint32
+	sha256 string
+}
+
+const (
+	directoryEntryFile      = "file"
+	directoryEntryDirectory = "directory"
+	directoryEntrySymlink   = "symlink"
+	directoryEntryOther     = "other"
+)
+
+var directoryEntryKinds = map[string]string{
+	"f": directoryEntryFile,
+	"d": directoryEntryDirectory,
+	"l": directoryEntrySymlink,
+}
+
+// parseSha256sum parses the output of `sha256sum` run against files under path, keyed by their path relative to it.
+// Names containing backslash or newline are escaped by sha256sum and marked with a leading backslash. `-z`, which
+// avoids the escaping, is not used since it requires coreutils 8.30 or later.
+func parseSha256sum(out, path string) (sums map[string]string) {
+	sums = map[string]string{}
+	for _, line := range strings.Split(out, "\n") {
+		escaped := strings.HasPrefix(line, "\\")
+		sum, p, ok := strings.Cut(strings.TrimPrefix(line, "\\"), "  ")
+		if !ok {
+			continue
+		}
+		if escaped {
+			p = strings.NewReplacer(`\\`, `\`, `\n`, "\n", `\r`, "\r").Replace(p)
+		}
+		sums[strings.TrimPrefix(p, strings.TrimSuffix(path, "/")+"/")] = sum
+	}
+	return
+}
+
+// listDirectory returns entries below path sorted by name, using a single remote execution.
+// maxDepth of 0 means unlimited and pattern, if not empty, is matched against the entry base name.
+func (l *linux) listDirectory(ctx context.Context, path, pattern string, maxDepth int) (entries []directoryEntry, err error) {
+	args := []string{"find", path, "-mindepth", "1"}
+	if maxDepth > 0 {
+		args = append(args, "-maxdepth", strconv.Itoa(maxDepth))
+	}
+	if pattern != "" {
+		args = append(args, "-name", pattern)
+	}
+	cmd := fmt.Sprintf(`{ %s && printf '\0' && %s ;}`,
+		shellescape.QuoteCommand(append(args, "-printf", `%y %s %m %U %G %P\0`)),
+		shellescape.QuoteCommand(append(args, "-type", "f", "-exec", "sha256sum", "{}", "+")))
+	stdout := new(bytes.Buffer)
+	if err = l.exec(ctx, &remote.Cmd{Command: cmd, Stdout: stdout}); err != nil {
+		return
+	}
+
+	tokens := strings.Split(stdout.String(), "\x00")
+	i := slices.Index(tokens, "") // separates entries from checksums
+	if i < 0 {
+		return nil, fmt.Errorf("malformed output of %q", cmd)
+	}
+	sums := parseSha256sum(strings.Join(tokens[i+1:], "\x00"), path)
+	for _, t := range tokens[:i] {
+		parts := strings.SplitN(t, " ", 6)
+		if len(parts) != 6 {
+			return nil, fmt.Errorf("malformed output of %q: %q", cmd, t)
+		}
+		e := directoryEntry{name: parts[5], kind: directoryEntryKinds[parts[0]], mode: normalizeMode(parts[2])}
+		if e.kind == "" {
+			e.kind = directoryEntryOther
+		}
+		if e.size, err = strconv.ParseInt(parts[1], 10, 64); err != nil {
+			return nil, fmt.Errorf("while parsing size of %q: %w", e.name, err)
+		}
+		owner, err := strconv.ParseUint(parts[3], 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("while parsing owner id of %q: %w", e.name, err)
+		}
+		group, err := strconv.ParseUint(parts[4], 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("while parsing group id of %q: %w", e.name, err)
+		}
+		e.owner, e.group, e.sha256 = uint32(owner), uint32(group), sums[e.name]
+		entries = append(entries, e)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].name < entries[j].name })
+	return
 }
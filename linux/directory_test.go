@@ -0,0 +1,29 @@
+package linux
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSha256sum(t *testing.T) {
+	tests := []struct {
+		name     string
+		out      string
+		path     string
+		expected map[string]string
+	}{
+		{name: "empty", out: "", path: "/tmp/dir", expected: map[string]string{}},
+		{name: "plain", out: "aaa  /tmp/dir/a.txt\nbbb  /tmp/dir/sub/b c.txt\n", path: "/tmp/dir/",
+			expected: map[string]string{"a.txt": "aaa", "sub/b c.txt": "bbb"}},
+		{name: "newline", out: "\\aaa  /tmp/dir/a\\nb.txt\n", path: "/tmp/dir",
+			expected: map[string]string{"a\nb.txt": "aaa"}},
+		{name: "backslash", out: "\\aaa  /tmp/dir/a\\\\nb.txt\n", path: "/tmp/dir",
+			expected: map[string]string{`a\nb.txt`: "aaa"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, parseSha256sum(tt.out, tt.path))
+		})
+	}
+}
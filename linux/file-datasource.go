@@ -0,0 +1,158 @@
+package linux
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/spf13/cast"
+)
+
+const (
+	attrFileDataProviderOverride = "provider_override"
+	attrFileDataPath             = "path"
+	attrFileDataContent          = "content"
+	attrFileDataContentBase64    = "content_base64"
+	attrFileDataSHA256           = "sha256"
+	attrFileDataSize             = "size"
+	attrFileDataOwner            = "owner"
+	attrFileDataGroup            = "group"
+	attrFileDataOwnerName        = "owner_name"
+	attrFileDataGroupName        = "group_name"
+	attrFileDataMode             = "mode"
+	attrFileDataMtime            = "mtime"
+	attrFileDataExists           = "exists"
+)
+
+var schemaFileDataSource = map[string]*schema.Schema{
+	attrFileDataProviderOverride: {
+		Type:     schema.TypeList,
+		Optional: true,
+		MaxItems: 1,
+		Elem: &schema.Resource{
+			Schema: subSchemaProviderOverride,
+		},
+	},
+
+	attrFileDataPath: {
+		Type:     schema.TypeString,
+		Required: true,
+	},
+	attrFileDataContent: {
+		Type:        schema.TypeString,
+		Computed:    true,
+		Description: "Content of the file. Binary content should be read from `content_base64` instead",
+	},
+	attrFileDataContentBase64: {
+		Type:        schema.TypeString,
+		Computed:    true,
+		Description: "Base64 encoded content of the file",
+	},
+	attrFileDataSHA256: {
+		Type:        schema.TypeString,
+		Computed:    true,
+		Description: "Hex encoded SHA256 checksum of the content",
+	},
+	attrFileDataSize: {
+		Type:        schema.TypeInt,
+		Computed:    true,
+		Description: "Size of the file in bytes",
+	},
+	attrFileDataOwner: {
+		Type:     schema.TypeInt,
+		Computed: true,
+	},
+	attrFileDataGroup: {
+		Type:     schema.TypeInt,
+		Computed: true,
+	},
+	attrFileDataOwnerName: {
+		Type:     schema.TypeString,
+		Computed: true,
+	},
+	attrFileDataGroupName: {
+		Type:     schema.TypeString,
+		Computed: true,
+	},
+	attrFileDataMode: {
+		Type:     schema.TypeString,
+		Computed: true,
+	},
+	attrFileDataMtime: {
+		Type:        schema.TypeString,
+		Computed:    true,
+		Description: "Last modification time of the file in RFC3339 format",
+	},
+	attrFileDataExists: {
+		Type:        schema.TypeBool,
+		Computed:    true,
+		Description: "Whether the file exists. Other attributes are empty when it doesn't",
+	},
+}
+
+type handlerFileDataSource struct{}
+
+func (handlerFileDataSource) updateResourceData(f *file, mtime time.Time, rd *schema.ResourceData) (err error) {
+	sum := sha256.Sum256([]byte(f.content))
+	m := map[string]interface{}{
+		attrFileDataContent:       f.content,
+		attrFileDataContentBase64: base64.StdEncoding.EncodeToString([]byte(f.content)),
+		attrFileDataSHA256:        hex.EncodeToString(sum[:]),
+		attrFileDataSize:          len(f.content),
+		attrFileDataOwner:         f.permission.owner,
+		attrFileDataGroup:         f.permission.group,
+		attrFileDataOwnerName:     f.permission.ownerName,
+		attrFileDataGroupName:     f.permission.groupName,
+		attrFileDataMode:          f.permission.mode,
+		attrFileDataMtime:         mtime.UTC().Format(time.RFC3339),
+		attrFileDataExists:        true,
+	}
+	for k, v := range m {
+		if err = rd.Set(k, v); err != nil {
+			return
+		}
+	}
+	return
+}
+
+func (h handlerFileDataSource) Read(ctx context.Context, rd *schema.ResourceData, meta interface{}) (d diag.Diagnostics) {
+	l, err := getLinux(meta.(*linuxPool), rd)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	rd.SetId("static")
+	path := cast.ToString(rd.Get(attrFileDataPath))
+	f, err := l.readFile(ctx, path, false)
+	if errors.Is(err, errPathNotExist) {
+		if err = rd.Set(attrFileDataExists, false); err != nil {
+			return diag.FromErr(err)
+		}
+		return
+	}
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	mtime, err := l.getMtime(ctx, path)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err = h.updateResourceData(f, mtime, rd); err != nil {
+		return diag.FromErr(err)
+	}
+	return
+}
+
+func fileDataSource() *schema.Resource {
+	h := handlerFileDataSource{}
+	return &schema.Resource{
+		Schema:      schemaFileDataSource,
+		ReadContext: h.Read,
+	}
+}
@@ -0,0 +1,76 @@
+package linux
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccLinuxDataFileBasic(t *testing.T) {
+	conf := tfConf{
+		Provider: testAccProvider,
+		File: tfmap{
+			attrFilePath:    fmt.Sprintf(`"/tmp/linux/%s"`, acctest.RandString(16)),
+			attrFileContent: `"helloworld"`,
+			attrFileMode:    `"640"`,
+		},
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  testAccPreCheckConnection(t),
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccLinuxDataFileBasicConfig(t, conf),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.linux_file.file", attrFileDataExists, "true"),
+					resource.TestCheckResourceAttr("data.linux_file.file", attrFileDataContent, "helloworld"),
+					resource.TestCheckResourceAttr("data.linux_file.file", attrFileDataContentBase64, "aGVsbG93b3JsZA=="),
+					resource.TestCheckResourceAttr("data.linux_file.file", attrFileDataSHA256,
+						"936a185caaa266bb9cbe981e9e05cb78cd732b0b3280eb944412bb6f8f8f07af"),
+					resource.TestCheckResourceAttr("data.linux_file.file", attrFileDataSize, "10"),
+					resource.TestCheckResourceAttr("data.linux_file.file", attrFileDataMode, "640"),
+					resource.TestCheckResourceAttr("data.linux_file.file", attrFileDataOwnerName, "root"),
+					resource.TestCheckResourceAttrSet("data.linux_file.file", attrFileDataMtime),
+					resource.TestCheckResourceAttr("data.linux_file.notexist", attrFileDataExists, "false"),
+				),
+			},
+		},
+	})
+}
+
+func testAccLinuxDataFileBasicConfig(t *testing.T, conf tfConf) (s string) {
+	tf := heredoc.Doc(`
+		provider "linux" {
+			alias = "test"
+		    {{- .Provider.Serialize | nindent 4 }}
+		}
+
+		resource "linux_file" "file" {
+			provider = "linux.test"
+
+		    {{- .File.Serialize | nindent 4 }}
+		}
+
+		data "linux_file" "file" {
+			provider = "linux.test"
+
+		    path = linux_file.file.path
+		}
+
+		data "linux_file" "notexist" {
+			provider = "linux.test"
+
+		    path = "${linux_file.file.path}.notexist"
+		}
+	`)
+
+	s, err := conf.compile(tf)
+	t.Log(s)
+	require.NoError(t, err, "compile template failed")
+	return
+}
@@ -0,0 +1,187 @@
+package linux
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"github.com/google/uuid"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/spf13/cast"
+)
+
+const (
+	attrFileLineProviderOverride = "provider_override"
+	attrFileLinePath             = "path"
+	attrFileLineLine             = "line"
+	attrFileLineRegexp           = "regexp"
+	attrFileLineBlock            = "block"
+	attrFileLineMarker           = "marker"
+	attrFileLineState            = "state"
+	attrFileLineCreate           = "create"
+	attrFileLineChanged          = "changed"
+)
+
+var schemaFileLineResource = map[string]*schema.Schema{
+	attrFileLineProviderOverride: {
+		Type:     schema.TypeList,
+		Optional: true,
+		MaxItems: 1,
+		Elem: &schema.Resource{
+			Schema: subSchemaProviderOverride,
+		},
+	},
+
+	attrFileLinePath: {
+		Type:     schema.TypeString,
+		Required: true,
+		ForceNew: true,
+	},
+	attrFileLineLine: {
+		Type:          schema.TypeString,
+		Optional:      true,
+		ForceNew:      true,
+		ConflictsWith: []string{attrFileLineBlock},
+		AtLeastOneOf:  []string{attrFileLineLine, attrFileLineRegexp, attrFileLineBlock},
+		Description:   "The line to ensure. Required when `state` is `present` and `block` is not used",
+	},
+	attrFileLineRegexp: {
+		Type:          schema.TypeString,
+		Optional:      true,
+		ForceNew:      true,
+		ValidateFunc:  validation.StringIsValidRegExp,
+		ConflictsWith: []string{attrFileLineBlock},
+		Description:   "Regular expression matching the line to be replaced when `state` is `present`, or removed when `state` is `absent`",
+	},
+	attrFileLineBlock: {
+		Type:        schema.TypeString,
+		Optional:    true,
+		ForceNew:    true,
+		Description: "Multi-line text surrounded by `marker` lines",
+	},
+	attrFileLineMarker: {
+		Type:     schema.TypeString,
+		Optional: true,
+		ForceNew: true,
+		Default:  "# {mark} TERRAFORM MANAGED BLOCK",
+		ValidateFunc: validation.StringMatch(regexp.MustCompile(regexp.QuoteMeta(fileLineMarkerPlaceholder)),
+			"must contain "+fileLineMarkerPlaceholder),
+		Description: "Template of lines surrounding `block`, where `{mark}` is replaced by `BEGIN` and `END`",
+	},
+	attrFileLineState: {
+		Type:         schema.TypeString,
+		Optional:     true,
+		ForceNew:     true,
+		Default:      fileLineStatePresent,
+		ValidateFunc: validation.StringInSlice([]string{fileLineStatePresent, fileLineStateAbsent}, false),
+	},
+	attrFileLineCreate: {
+		Type:        schema.TypeBool,
+		Optional:    true,
+		ForceNew:    true,
+		Default:     false,
+		Description: "If true, the file will be created when it doesn't exist",
+	},
+	attrFileLineChanged: {
+		Type:        schema.TypeBool,
+		Computed:    true,
+		Description: "Whether the file was changed when the resource was created, which is when destroy reverts it",
+	},
+}
+
+type handlerFileLineResource struct{}
+
+func (handlerFileLineResource) newFileLine(rd *schema.ResourceData) (fl *fileLine) {
+	if rd == nil {
+		return
+	}
+	return &fileLine{
+		path:   cast.ToString(rd.Get(attrFileLinePath)),
+		line:   cast.ToString(rd.Get(attrFileLineLine)),
+		regexp: cast.ToString(rd.Get(attrFileLineRegexp)),
+		block:  cast.ToString(rd.Get(attrFileLineBlock)),
+		marker: cast.ToString(rd.Get(attrFileLineMarker)),
+		state:  cast.ToString(rd.Get(attrFileLineState)),
+		create: cast.ToBool(rd.Get(attrFileLineCreate)),
+	}
+}
+
+func (h handlerFileLineResource) Read(ctx context.Context, rd *schema.ResourceData, meta interface{}) (d diag.Diagnostics) {
+	l, err := getLinux(meta.(*linuxPool), rd)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	ok, err := l.isFileLineEnsured(ctx, h.newFileLine(rd))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if !ok {
+		rd.SetId("") // drifted, will be ensured again
+	}
+	return
+}
+
+func (h handlerFileLineResource) Create(ctx context.Context, rd *schema.ResourceData, meta interface{}) (d diag.Diagnostics) {
+	l, err := getLinux(meta.(*linuxPool), rd)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	changed, err := l.ensureFileLine(ctx, h.newFileLine(rd))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	_ = rd.Set(attrFileLineChanged, changed)
+
+	id, err := uuid.NewRandom()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	rd.SetId(id.String())
+	return
+}
+
+func (h handlerFileLineResource) Update(ctx context.Context, rd *schema.ResourceData, meta interface{}) (d diag.Diagnostics) {
+	return // only `provider_override` is updatable in place, which doesn't touch the file
+}
+
+func (h handlerFileLineResource) Delete(ctx context.Context, rd *schema.ResourceData, meta interface{}) (d diag.Diagnostics) {
+	if !cast.ToBool(rd.Get(attrFileLineChanged)) {
+		return // the line or block was already there, leave it to whoever put it
+	}
+	l, err := getLinux(meta.(*linuxPool), rd)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := l.revertFileLine(ctx, h.newFileLine(rd)); err != nil {
+		return diag.FromErr(err)
+	}
+	return
+}
+
+func (h handlerFileLineResource) CustomizeDiff(ctx context.Context, rd *schema.ResourceDiff, meta interface{}) (err error) {
+	if !rd.NewValueKnown(attrFileLineState) || !rd.NewValueKnown(attrFileLineLine) || !rd.NewValueKnown(attrFileLineBlock) {
+		return
+	}
+	if cast.ToString(rd.Get(attrFileLineState)) == fileLineStatePresent &&
+		cast.ToString(rd.Get(attrFileLineLine)) == "" && cast.ToString(rd.Get(attrFileLineBlock)) == "" {
+		return fmt.Errorf("`%s` is required when `%s` is `%s` and `%s` is not set",
+			attrFileLineLine, attrFileLineState, fileLineStatePresent, attrFileLineBlock)
+	}
+	return
+}
+
+func fileLineResource() *schema.Resource {
+	var hflr handlerFileLineResource
+	return &schema.Resource{
+		Schema:        schemaFileLineResource,
+		CreateContext: hflr.Create,
+		ReadContext:   hflr.Read,
+		UpdateContext: hflr.Update,
+		DeleteContext: hflr.Delete,
+		CustomizeDiff: hflr.CustomizeDiff,
+	}
+}
@@ -0,0 +1,147 @@
+package linux
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccLinuxFileLineBasic(t *testing.T) {
+	path := fmt.Sprintf(`"/tmp/linux/%s"`, acctest.RandString(16))
+	conf0 := tfConf{
+		Provider: testAccProvider,
+		FileLine: tfmap{
+			attrFileLinePath:   path,
+			attrFileLineRegexp: `"^#?Port "`,
+		},
+		Extra: tfmap{"expected": `"Port 2222"`},
+	}
+	conf1 := tfConf{
+		Provider: testAccProvider,
+		FileLine: tfmap{
+			attrFileLinePath:   path,
+			attrFileLineLine:   `"Port 2222"`,
+			attrFileLineRegexp: `"^#?Port "`,
+		},
+		Extra: tfmap{"expected": `"Port 2222"`},
+	}
+	conf2 := conf1.Copy(func(tc *tfConf) {
+		tc.FileLine = tfmap{
+			attrFileLinePath:  path,
+			attrFileLineBlock: `"ListenAddress 127.0.0.1\n"`,
+		}
+		tc.Extra.With("expected", `"ListenAddress 127.0.0.1"`)
+	})
+	conf3 := conf1.Copy(func(tc *tfConf) {
+		tc.FileLine = tfmap{
+			attrFileLinePath:   path,
+			attrFileLineRegexp: `"^UseDNS"`,
+			attrFileLineState:  `"absent"`,
+		}
+		tc.Extra.With("expected", `"PermitRootLogin no"`)
+	})
+	conf4 := conf1.Copy(func(tc *tfConf) {
+		tc.FileLine = tfmap{
+			attrFileLinePath: path,
+			attrFileLineLine: `"PermitRootLogin no"`,
+		}
+		tc.Extra.With("expected", `"PermitRootLogin no"`)
+	})
+
+	resource.Test(t, resource.TestCase{
+		ExternalProviders: map[string]resource.ExternalProvider{"null": {}},
+		PreCheck:          testAccPreCheckConnection(t),
+		Providers:         testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccLinuxFileLineBasicConfig(t, conf0),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile("`line` is required"),
+			},
+			{
+				Config: testAccLinuxFileLineBasicConfig(t, conf1),
+				Check:  resource.TestCheckResourceAttr("linux_file_line.line", attrFileLineChanged, "true"),
+			},
+			{
+				Config: testAccLinuxFileLineBasicConfig(t, conf2),
+			},
+			{
+				Config: testAccLinuxFileLineBasicConfig(t, conf3),
+			},
+			{
+				Config: testAccLinuxFileLineBasicConfig(t, conf4), // pre-existing line is kept on destroy
+				Check:  resource.TestCheckResourceAttr("linux_file_line.line", attrFileLineChanged, "false"),
+			},
+		},
+	})
+}
+
+func testAccLinuxFileLineBasicConfig(t *testing.T, conf tfConf) (s string) {
+	tf := heredoc.Doc(`
+		provider "linux" {
+			alias = "test"
+		    {{- .Provider.Serialize | nindent 4 }}
+		}
+
+		resource "null_resource" "file" {
+		    triggers = {
+		        path = {{ .FileLine.path }}
+		    }
+		    connection {
+		        type = "ssh"
+		        {{- .Provider.Serialize | nindent 8 }}
+		    }
+		    provisioner "remote-exec" {
+		        inline = [
+		            <<-EOF
+		                mkdir -p "$(dirname '${self.triggers["path"]}')"
+		                printf '#Port 22\nPermitRootLogin no\nUseDNS no\n' > '${self.triggers["path"]}'
+		            EOF
+		        ]
+		    }
+		    provisioner "remote-exec" {
+		        when = destroy
+		        inline = [
+		            <<-EOF
+		                [ "$(cat '${self.triggers["path"]}' | xargs)" == "PermitRootLogin no" ] || exit 100
+		                rm -f '${self.triggers["path"]}'
+		            EOF
+		        ]
+		    }
+		}
+
+		resource "linux_file_line" "line" {
+			provider = "linux.test"
+		    depends_on = [ null_resource.file ]
+
+		    {{- .FileLine.Serialize | nindent 4 }}
+		}
+
+		resource "null_resource" "create_validator" {
+		    triggers = {
+		        id = linux_file_line.line.id
+		    }
+		    connection {
+		        type = "ssh"
+		        {{- .Provider.Serialize | nindent 8 }}
+		    }
+		    provisioner "remote-exec" {
+		        inline = [
+		            <<-EOF
+		                grep -qx {{ .Extra.expected }} {{ .FileLine.path }} || exit 101
+		            EOF
+		        ]
+		    }
+		}
+	`)
+
+	s, err := conf.compile(tf)
+	t.Log(s)
+	require.NoError(t, err, "compile template failed")
+	return
+}
@@ -0,0 +1,218 @@
+package linux
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	fileLineStatePresent = "present"
+	fileLineStateAbsent  = "absent"
+
+	fileLineMarkerPlaceholder = "{mark}"
+)
+
+type fileLine struct {
+	path   string
+	line   string
+	regexp string
+	block  string
+	marker string
+	state  string
+	create bool
+}
+
+func (fl *fileLine) markers() (begin, end string) {
+	return strings.ReplaceAll(fl.marker, fileLineMarkerPlaceholder, "BEGIN"),
+		strings.ReplaceAll(fl.marker, fileLineMarkerPlaceholder, "END")
+}
+
+// apply returns content with the line or block ensured according to the state.
+func (fl *fileLine) apply(content string) (s string, err error) {
+	lines, eol := splitLines(content)
+	switch {
+	case fl.block != "":
+		if lines, err = fl.applyBlock(lines); err != nil {
+			return
+		}
+	default:
+		if lines, err = fl.applyLine(lines); err != nil {
+			return
+		}
+	}
+	if s = joinLines(lines, eol); s != content {
+		s = joinLines(lines, true) // modified content always ends with newline
+	}
+	return
+}
+
+func (fl *fileLine) applyBlock(lines []string) ([]string, error) {
+	begin, end := fl.markers()
+	i, j := findBlock(lines, begin, end)
+	if i >= 0 && j < 0 {
+		return nil, fmt.Errorf("marker %q at line %d has no matching %q", begin, i+1, end)
+	}
+
+	var block []string
+	if fl.state == fileLineStatePresent {
+		block = append([]string{begin}, strings.Split(strings.TrimSuffix(fl.block, "\n"), "\n")...)
+		block = append(block, end)
+	}
+	if i < 0 {
+		return append(lines, block...), nil
+	}
+	return append(append(append([]string{}, lines[:i]...), block...), lines[j+1:]...), nil
+}
+
+func (fl *fileLine) applyLine(lines []string) (res []string, err error) {
+	match := func(s string) bool { return s == fl.line }
+	if fl.regexp != "" {
+		re, err := regexp.Compile(fl.regexp)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regexp %q: %w", fl.regexp, err)
+		}
+		match = re.MatchString
+	}
+
+	if fl.state == fileLineStateAbsent {
+		for _, l := range lines {
+			if !match(l) {
+				res = append(res, l)
+			}
+		}
+		return
+	}
+
+	last, exact := -1, false
+	for i, l := range lines {
+		if match(l) {
+			last = i
+		}
+		if l == fl.line {
+			exact = true
+		}
+	}
+	switch {
+	case last >= 0:
+		res = append(res, lines...)
+		res[last] = fl.line
+	case exact:
+		res = lines
+	default:
+		res = append(append(res, lines...), fl.line)
+	}
+	return
+}
+
+// revert returns content with the line or block that was ensured to be present removed.
+func (fl *fileLine) revert(content string) (s string, err error) {
+	if fl.state != fileLineStatePresent {
+		return content, nil // removed lines can't be restored
+	}
+	r := *fl
+	r.state, r.regexp = fileLineStateAbsent, ""
+	return r.apply(content)
+}
+
+// findBlock returns the indexes of the begin and end markers of the first block. j is -1 when the begin marker
+// is found without an end marker.
+func findBlock(lines []string, begin, end string) (i, j int) {
+	i, j = -1, -1
+	for n, l := range lines {
+		switch {
+		case l == begin && i < 0:
+			i = n
+		case l == end && i >= 0:
+			return i, n
+		}
+	}
+	return
+}
+
+func splitLines(content string) (lines []string, eol bool) {
+	if content == "" {
+		return nil, true
+	}
+	eol = strings.HasSuffix(content, "\n")
+	return strings.Split(strings.TrimSuffix(content, "\n"), "\n"), eol
+}
+
+func joinLines(lines []string, eol bool) string {
+	if len(lines) == 0 {
+		return ""
+	}
+	s := strings.Join(lines, "\n")
+	if eol {
+		s += "\n"
+	}
+	return s
+}
+
+func (l *linux) readFileLineContent(ctx context.Context, path string) (content string, exist bool, err error) {
+	if _, err = l.getPermission(ctx, path); errors.Is(err, errPathNotExist) {
+		return "", false, nil
+	}
+	if err != nil {
+		return
+	}
+	content, err = l.cat(ctx, path)
+	return content, err == nil, err
+}
+
+func (l *linux) isFileLineEnsured(ctx context.Context, fl *fileLine) (ok bool, err error) {
+	content, exist, err := l.readFileLineContent(ctx, fl.path)
+	if err != nil {
+		return
+	}
+	if !exist {
+		return fl.state == fileLineStateAbsent, nil
+	}
+	s, err := fl.apply(content)
+	if err != nil {
+		return
+	}
+	return s == content, nil
+}
+
+// ensureFileLine ensures the line or block, and reports whether the file had to be changed for it.
+func (l *linux) ensureFileLine(ctx context.Context, fl *fileLine) (changed bool, err error) {
+	if fl == nil {
+		return false, errNil
+	}
+	content, exist, err := l.readFileLineContent(ctx, fl.path)
+	if err != nil {
+		return
+	}
+	if !exist {
+		if fl.state == fileLineStateAbsent {
+			return
+		}
+		if !fl.create {
+			return false, fmt.Errorf("path '%s' doesn't exist", fl.path)
+		}
+	}
+
+	s, err := fl.apply(content)
+	if err != nil || (exist && s == content) {
+		return
+	}
+	return true, l.replace(ctx, fl.path, strings.NewReader(s))
+}
+
+func (l *linux) revertFileLine(ctx context.Context, fl *fileLine) (err error) {
+	if fl == nil {
+		return
+	}
+	content, exist, err := l.readFileLineContent(ctx, fl.path)
+	if err != nil || !exist {
+		return
+	}
+	s, err := fl.revert(content)
+	if err != nil || s == content {
+		return
+	}
+	return l.replace(ctx, fl.path, strings.NewReader(s))
+}
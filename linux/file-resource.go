@@ -3,9 +3,10 @@ package linux
 import (
 	"context"
 	"errors"
-	"regexp"
+	"fmt"
 
 	"github.com/google/uuid"
+	"github.com/hashicorp/go-cty/cty"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
@@ -13,15 +14,41 @@ import (
 )
 
 const (
-	attrFileProviderOverride = "provider_override"
-	attrFilePath             = "path"
-	attrFileContent          = "content"
-	attrFileOwner            = "owner"
-	attrFileGroup            = "group"
-	attrFileMode             = "mode"
-	attrFileIgnoreContent    = "ignore_content"
-	attrFileOverwrite        = "overwrite"
-	attrFileRecyclePath      = "recycle_path"
+	attrFileProviderOverride   = "provider_override"
+	attrFilePath               = "path"
+	attrFileContent            = "content"
+	attrFileContentWO          = "content_wo"
+	attrFileContentWOVersion   = "content_wo_version"
+	attrFileOwner              = "owner"
+	attrFileGroup              = "group"
+	attrFileOwnerName          = "owner_name"
+	attrFileGroupName          = "group_name"
+	attrFileMode               = "mode"
+	attrFileIgnoreContent      = "ignore_content"
+	attrFileOverwrite          = "overwrite"
+	attrFileRecyclePath        = "recycle_path"
+	attrFileRecycleRetention   = "recycle_retention"
+	attrFileRecycleMaxItems    = "recycle_max_items"
+	attrFileRestoreFromRecycle = "restore_from_recycle"
+	attrFileDeleteStrategy     = "delete_strategy"
+	attrFileOnCreate           = "on_create"
+	attrFileOnChange           = "on_change"
+	attrFileOnDestroy          = "on_destroy"
+	attrFileHookWorkdir        = "hook_working_directory"
+	attrFileHookEnvironment    = "hook_environment"
+	attrFileBackup             = "backup"
+	attrFileBackupPath         = "backup_path"
+	attrFileBackupLocation     = "backup_location"
+	attrFileBackupLocations    = "backup_locations"
+	attrFileProtectModified    = "protect_modified"
+	attrFileForce              = "force"
+	attrFileAppliedSHA256      = "applied_sha256"
+	attrFileAppliedSalt        = "applied_sha256_salt"
+	attrFileContentDiff        = "content_diff"
+	attrFileContentDiffRedact  = "content_diff_redact"
+	attrFileSelinuxContext     = "selinux_context"
+	attrFileACL                = "acl"
+	attrFileXattrs             = "xattrs"
 )
 
 var schemaFileResource = map[string]*schema.Schema{
@@ -46,21 +73,68 @@ var schemaFileResource = map[string]*schema.Schema{
 			return cast.ToBool(d.Get(attrFileIgnoreContent))
 		},
 	},
-	attrFileOwner: {
-		Type:     schema.TypeInt,
+	attrFileContentDiff: {
+		Type:        schema.TypeString,
+		Computed:    true,
+		Description: "Unified diff between the remote content and `content` of the latest update that changed `content`",
+	},
+	attrFileContentDiffRedact: {
+		Type:     schema.TypeList,
 		Optional: true,
-		Default:  0,
+		Elem: &schema.Schema{
+			Type:         schema.TypeString,
+			ValidateFunc: validation.StringIsValidRegExp,
+		},
+		Description: "Regular expressions matched against each line of `content_diff`. Matching lines are redacted",
+	},
+	attrFileContentWO: {
+		Type:          schema.TypeString,
+		Optional:      true,
+		WriteOnly:     true,
+		Sensitive:     true,
+		ConflictsWith: []string{attrFileContent, attrFileIgnoreContent},
+		RequiredWith:  []string{attrFileContentWOVersion},
+		Description:   "Write-only content of the file which is never stored in state. Only written when `content_wo_version` changes",
+	},
+	attrFileContentWOVersion: {
+		Type:         schema.TypeInt,
+		Optional:     true,
+		ValidateFunc: validation.IntAtLeast(1),
+		RequiredWith: []string{attrFileContentWO},
+		Description:  "Version of `content_wo`. Read as `-1` when the remote content no longer matches `applied_sha256`",
+	},
+	attrFileOwner: {
+		Type:         schema.TypeInt,
+		Optional:     true,
+		Computed:     true,
+		ValidateFunc: validateID,
+	},
+	attrFileOwnerName: {
+		Type:          schema.TypeString,
+		Optional:      true,
+		Computed:      true,
+		ConflictsWith: []string{attrFileOwner},
+		Description:   "User name of the file, resolved on remote. Conflicts with `owner`",
 	},
 	attrFileGroup: {
-		Type:     schema.TypeInt,
-		Optional: true,
-		Default:  0,
+		Type:         schema.TypeInt,
+		Optional:     true,
+		Computed:     true,
+		ValidateFunc: validateID,
+	},
+	attrFileGroupName: {
+		Type:          schema.TypeString,
+		Optional:      true,
+		Computed:      true,
+		ConflictsWith: []string{attrFileGroup},
+		Description:   "Group name of the file, resolved on remote. Conflicts with `group`",
 	},
 	attrFileMode: {
-		Type:         schema.TypeString,
-		Optional:     true,
-		Default:      "644",
-		ValidateFunc: validation.StringMatch(regexp.MustCompile("[0-7]{3}"), "Invalid linux permission"),
+		Type:             schema.TypeString,
+		Optional:         true,
+		Computed:         true,
+		ValidateFunc:     validateMode,
+		DiffSuppressFunc: diffSuppressMode(false),
 	},
 	attrFileIgnoreContent: {
 		Type:        schema.TypeBool,
@@ -80,11 +154,144 @@ var schemaFileResource = map[string]*schema.Schema{
 		Default:     "",
 		Description: "Path to parent directory of a generated-unix-timestamp directory where the file will be placed on destroy",
 	},
+	attrFileRecycleRetention: {
+		Type:         schema.TypeString,
+		Optional:     true,
+		Default:      "",
+		ValidateFunc: validateRetention,
+		Description:  "Duration, e.g. `720h`, after which generations inside `recycle_path` are removed whenever another item is recycled",
+	},
+	attrFileRecycleMaxItems: {
+		Type:         schema.TypeInt,
+		Optional:     true,
+		Default:      0,
+		ValidateFunc: validation.IntAtLeast(0),
+		Description:  "Maximum number of generations kept inside `recycle_path`. The oldest ones are removed whenever another item is recycled",
+	},
+	attrFileDeleteStrategy: {
+		Type:         schema.TypeString,
+		Optional:     true,
+		Default:      "",
+		ValidateFunc: validation.StringInSlice(deleteStrategies, false),
+		Description:  "What happens to the file on destroy, one of `remove`, `recycle`, `shred` or `retain`. Default to `recycle` when `recycle_path` is set, otherwise `remove`",
+	},
+	attrFileRestoreFromRecycle: {
+		Type:        schema.TypeBool,
+		Optional:    true,
+		Default:     false,
+		Description: "If true, the latest file recycled from the same path inside `recycle_path` will be restored on create. Requires `ignore_content`",
+	},
+	attrFileOnCreate: {
+		Type:        schema.TypeString,
+		Optional:    true,
+		Default:     "",
+		Description: "Commands that will be executed after the file is created",
+	},
+	attrFileOnChange: {
+		Type:        schema.TypeString,
+		Optional:    true,
+		Default:     "",
+		Description: "Commands that will be executed after the file path, content, or permission is changed",
+	},
+	attrFileOnDestroy: {
+		Type:        schema.TypeString,
+		Optional:    true,
+		Default:     "",
+		Description: "Commands that will be executed after the file is destroyed",
+	},
+	attrFileHookWorkdir: {
+		Type:        schema.TypeString,
+		Optional:    true,
+		Default:     ".",
+		Description: "The working directory where hook commands are executed",
+	},
+	attrFileHookEnvironment: {
+		Type:        schema.TypeMap,
+		Optional:    true,
+		Elem:        schema.TypeString,
+		Description: "Environment variables that will be available to hook commands",
+	},
+	attrFileBackup: {
+		Type:        schema.TypeBool,
+		Optional:    true,
+		Default:     false,
+		Description: "If true, existing file on remote will be copied to a generated-unix-timestamp-suffixed path before being replaced on create or update",
+	},
+	attrFileBackupPath: {
+		Type:        schema.TypeString,
+		Optional:    true,
+		Default:     "",
+		Description: "Path to directory where the backup will be placed. Default to the parent directory of the file. Setting this implies `backup` to be true",
+	},
+	attrFileBackupLocation: {
+		Type:        schema.TypeString,
+		Computed:    true,
+		Description: "Path of the first backup created by the latest create or update that backed up anything",
+	},
+	attrFileBackupLocations: {
+		Type:        schema.TypeList,
+		Computed:    true,
+		Elem:        &schema.Schema{Type: schema.TypeString},
+		Description: "Paths of all backups created by the latest create or update that backed up anything",
+	},
+	attrFileProtectModified: {
+		Type:        schema.TypeBool,
+		Optional:    true,
+		Default:     false,
+		Description: "If true, the file won't be overwritten or deleted when it has been modified outside of terraform since the last apply",
+	},
+	attrFileForce: {
+		Type:        schema.TypeBool,
+		Optional:    true,
+		Default:     false,
+		Description: "If true, modification made outside of terraform will be discarded even when `protect_modified` is true",
+	},
+	attrFileAppliedSHA256: {
+		Type:        schema.TypeString,
+		Computed:    true,
+		Description: "SHA256 checksum of the content when it was last applied, prefixed with `applied_sha256_salt` for write-only content",
+	},
+	attrFileAppliedSalt: {
+		Type:        schema.TypeString,
+		Computed:    true,
+		Description: "Random salt of `applied_sha256` generated whenever write-only content is applied, so that the checksum doesn't reveal the content",
+	},
+	attrFileSelinuxContext: {
+		Type:        schema.TypeString,
+		Optional:    true,
+		Computed:    true,
+		Description: "SELinux security context of the file, e.g. `system_u:object_r:httpd_sys_content_t:s0`",
+	},
+	attrFileACL: {
+		Type:        schema.TypeSet,
+		Optional:    true,
+		Computed:    true,
+		Elem:        &schema.Schema{Type: schema.TypeString},
+		Description: "POSIX ACL entries of the file as printed by `getfacl`, excluding the base entries",
+	},
+	attrFileXattrs: {
+		Type:             schema.TypeMap,
+		Optional:         true,
+		Computed:         true,
+		Elem:             &schema.Schema{Type: schema.TypeString},
+		ValidateDiagFunc: validateXattrs,
+		Description:      "Extended attributes of the file in the `user.` namespace",
+	},
 }
 
 type handlerFileResource struct{}
 
-func (handlerFileResource) newFile(rd *schema.ResourceData) (f *file) {
+func (handlerFileResource) newHook(rd *schema.ResourceData) hook {
+	return hook{
+		onCreate:  cast.ToString(rd.Get(attrFileOnCreate)),
+		onChange:  cast.ToString(rd.Get(attrFileOnChange)),
+		onDestroy: cast.ToString(rd.Get(attrFileOnDestroy)),
+		workdir:   cast.ToString(rd.Get(attrFileHookWorkdir)),
+		env:       cast.ToStringMapString(rd.Get(attrFileHookEnvironment)),
+	}
+}
+
+func (h handlerFileResource) newFile(rd *schema.ResourceData) (f *file) {
 	if rd == nil {
 		return
 	}
@@ -92,18 +299,47 @@ func (handlerFileResource) newFile(rd *schema.ResourceData) (f *file) {
 		path:    cast.ToString(rd.Get(attrFilePath)),
 		content: cast.ToString(rd.Get(attrFileContent)),
 		permission: permission{
-			owner: cast.ToUint16(rd.Get(attrFileOwner)),
-			group: cast.ToUint16(rd.Get(attrFileGroup)),
-			mode:  cast.ToString(rd.Get(attrFileMode)),
+			owner: cast.ToUint32(rd.Get(attrFileOwner)),
+			group: cast.ToUint32(rd.Get(attrFileGroup)),
+
+			ownerName: cast.ToString(rd.Get(attrFileOwnerName)),
+			groupName: cast.ToString(rd.Get(attrFileGroupName)),
+			mode:      cast.ToString(rd.Get(attrFileMode)),
 		},
-		ignoreContent: cast.ToBool(rd.Get(attrFileIgnoreContent)),
-		overwrite:     cast.ToBool(rd.Get(attrFileOverwrite)),
-		recyclePath:   cast.ToString(rd.Get(attrFileRecyclePath)),
+		ignoreContent:    cast.ToBool(rd.Get(attrFileIgnoreContent)),
+		contentWOVersion: cast.ToInt(rd.Get(attrFileContentWOVersion)),
+		overwrite:        cast.ToBool(rd.Get(attrFileOverwrite)),
+		recycle:          newRecycleBin(rd, attrFileRecyclePath, attrFileRecycleRetention, attrFileRecycleMaxItems),
+		deleteStrategy:   cast.ToString(rd.Get(attrFileDeleteStrategy)),
+		restore:          cast.ToBool(rd.Get(attrFileRestoreFromRecycle)),
+		hook:             h.newHook(rd),
+
+		backup:          cast.ToBool(rd.Get(attrFileBackup)),
+		backupPath:      cast.ToString(rd.Get(attrFileBackupPath)),
+		backupLocation:  cast.ToString(rd.Get(attrFileBackupLocation)),
+		backupLocations: cast.ToStringSlice(rd.Get(attrFileBackupLocations)),
+
+		protectModified: cast.ToBool(rd.Get(attrFileProtectModified)),
+		force:           cast.ToBool(rd.Get(attrFileForce)),
+		appliedSHA256:   cast.ToString(rd.Get(attrFileAppliedSHA256)),
+		appliedSalt:     cast.ToString(rd.Get(attrFileAppliedSalt)),
+
+		contentDiffRedact: cast.ToStringSlice(rd.Get(attrFileContentDiffRedact)),
+	}
+	if f.writeOnly() {
+		f.content = h.contentWO(rd)
 	}
+	f.attributes = unmanagedAttributes(rd.GetRawConfig(), attributes{
+		selinuxContext: cast.ToString(rd.Get(attrFileSelinuxContext)),
+		acl:            aclFromSet(rd.Get(attrFileACL)),
+		xattrs:         cast.ToStringMapString(rd.Get(attrFileXattrs)),
+	}, attrFileSelinuxContext, attrFileACL, attrFileXattrs)
+	f.permission = unmanagedPermission(rd.GetRawConfig(), f.permission,
+		attrFileOwner, attrFileOwnerName, attrFileGroup, attrFileGroupName, attrFileMode)
 	return
 }
 
-func (handlerFileResource) newDiffedFile(rd *schema.ResourceData) (old, new *file) {
+func (h handlerFileResource) newDiffedFile(rd *schema.ResourceData) (old, new *file) {
 	if rd == nil {
 		return
 	}
@@ -116,10 +352,22 @@ func (handlerFileResource) newDiffedFile(rd *schema.ResourceData) (old, new *fil
 	old.content, new.content = cast.ToString(o), cast.ToString(n)
 
 	o, n = rd.GetChange(attrFileOwner)
-	old.permission.owner, new.permission.owner = cast.ToUint16(o), cast.ToUint16(n)
+	old.permission.owner, new.permission.owner = cast.ToUint32(o), cast.ToUint32(n)
 
 	o, n = rd.GetChange(attrFileGroup)
-	old.permission.group, new.permission.group = cast.ToUint16(o), cast.ToUint16(n)
+	old.permission.group, new.permission.group = cast.ToUint32(o), cast.ToUint32(n)
+
+	o, n = rd.GetChange(attrFileOwnerName)
+	old.permission.ownerName, new.permission.ownerName = cast.ToString(o), cast.ToString(n)
+	if !configured(rd.GetRawConfig(), attrFileOwnerName) {
+		old.permission.ownerName, new.permission.ownerName = "", "" // compare by id
+	}
+
+	o, n = rd.GetChange(attrFileGroupName)
+	old.permission.groupName, new.permission.groupName = cast.ToString(o), cast.ToString(n)
+	if !configured(rd.GetRawConfig(), attrFileGroupName) {
+		old.permission.groupName, new.permission.groupName = "", "" // compare by id
+	}
 
 	o, n = rd.GetChange(attrFileMode)
 	old.permission.mode, new.permission.mode = cast.ToString(o), cast.ToString(n)
@@ -127,11 +375,89 @@ func (handlerFileResource) newDiffedFile(rd *schema.ResourceData) (old, new *fil
 	o, n = rd.GetChange(attrFileIgnoreContent)
 	old.ignoreContent, new.ignoreContent = cast.ToBool(o), cast.ToBool(n)
 
+	o, n = rd.GetChange(attrFileContentWOVersion)
+	old.contentWOVersion, new.contentWOVersion = cast.ToInt(o), cast.ToInt(n)
+	if new.writeOnly() {
+		if rd.HasChange(attrFileContentWOVersion) {
+			new.content = h.contentWO(rd)
+		} else {
+			new.ignoreContent = true // keep the remote content
+		}
+	}
+
 	o, n = rd.GetChange(attrFileOverwrite)
 	old.overwrite, new.overwrite = cast.ToBool(o), cast.ToBool(n)
 
 	o, n = rd.GetChange(attrFileRecyclePath)
-	old.recyclePath, new.recyclePath = cast.ToString(o), cast.ToString(n)
+	old.recycle.path, new.recycle.path = cast.ToString(o), cast.ToString(n)
+
+	o, n = rd.GetChange(attrFileRecycleRetention)
+	old.recycle.retention, new.recycle.retention = cast.ToString(o), cast.ToString(n)
+
+	o, n = rd.GetChange(attrFileRecycleMaxItems)
+	old.recycle.maxItems, new.recycle.maxItems = cast.ToInt(o), cast.ToInt(n)
+
+	o, n = rd.GetChange(attrFileDeleteStrategy)
+	old.deleteStrategy, new.deleteStrategy = cast.ToString(o), cast.ToString(n)
+
+	o, n = rd.GetChange(attrFileRestoreFromRecycle)
+	old.restore, new.restore = cast.ToBool(o), cast.ToBool(n)
+
+	o, n = rd.GetChange(attrFileOnCreate)
+	old.hook.onCreate, new.hook.onCreate = cast.ToString(o), cast.ToString(n)
+
+	o, n = rd.GetChange(attrFileOnChange)
+	old.hook.onChange, new.hook.onChange = cast.ToString(o), cast.ToString(n)
+
+	o, n = rd.GetChange(attrFileOnDestroy)
+	old.hook.onDestroy, new.hook.onDestroy = cast.ToString(o), cast.ToString(n)
+
+	o, n = rd.GetChange(attrFileHookWorkdir)
+	old.hook.workdir, new.hook.workdir = cast.ToString(o), cast.ToString(n)
+
+	o, n = rd.GetChange(attrFileHookEnvironment)
+	old.hook.env, new.hook.env = cast.ToStringMapString(o), cast.ToStringMapString(n)
+
+	o, n = rd.GetChange(attrFileBackup)
+	old.backup, new.backup = cast.ToBool(o), cast.ToBool(n)
+
+	o, n = rd.GetChange(attrFileBackupPath)
+	old.backupPath, new.backupPath = cast.ToString(o), cast.ToString(n)
+
+	old.backupLocation = cast.ToString(rd.Get(attrFileBackupLocation))
+	new.backupLocation = old.backupLocation
+	old.backupLocations = cast.ToStringSlice(rd.Get(attrFileBackupLocations))
+	new.backupLocations = old.backupLocations
+
+	o, n = rd.GetChange(attrFileProtectModified)
+	old.protectModified, new.protectModified = cast.ToBool(o), cast.ToBool(n)
+
+	o, n = rd.GetChange(attrFileForce)
+	old.force, new.force = cast.ToBool(o), cast.ToBool(n)
+
+	old.appliedSHA256 = cast.ToString(rd.Get(attrFileAppliedSHA256))
+	new.appliedSHA256 = old.appliedSHA256
+	old.appliedSalt = cast.ToString(rd.Get(attrFileAppliedSalt))
+	new.appliedSalt = old.appliedSalt
+
+	o, n = rd.GetChange(attrFileContentDiffRedact)
+	old.contentDiffRedact, new.contentDiffRedact = cast.ToStringSlice(o), cast.ToStringSlice(n)
+
+	o, n = rd.GetChange(attrFileSelinuxContext)
+	old.attributes.selinuxContext, new.attributes.selinuxContext = cast.ToString(o), cast.ToString(n)
+
+	o, n = rd.GetChange(attrFileACL)
+	old.attributes.acl, new.attributes.acl = aclFromSet(o), aclFromSet(n)
+
+	o, n = rd.GetChange(attrFileXattrs)
+	old.attributes.xattrs, new.attributes.xattrs = cast.ToStringMapString(o), cast.ToStringMapString(n)
+
+	old.permission = unmanagedPermission(rd.GetRawConfig(), old.permission,
+		attrFileOwner, attrFileOwnerName, attrFileGroup, attrFileGroupName, attrFileMode)
+	new.permission = unmanagedPermission(rd.GetRawConfig(), new.permission,
+		attrFileOwner, attrFileOwnerName, attrFileGroup, attrFileGroupName, attrFileMode)
+	old.attributes = unmanagedAttributes(rd.GetRawConfig(), old.attributes, attrFileSelinuxContext, attrFileACL, attrFileXattrs)
+	new.attributes = unmanagedAttributes(rd.GetRawConfig(), new.attributes, attrFileSelinuxContext, attrFileACL, attrFileXattrs)
 	return
 }
 
@@ -144,28 +470,112 @@ func (handlerFileResource) updateResourceData(f *file, rd *schema.ResourceData)
 	if err = rd.Set(attrFilePath, f.path); err != nil {
 		return
 	}
-	if err = rd.Set(attrFileOwner, f.permission.owner); err != nil {
-		return
+	if f.permission.owner != idUnmanaged {
+		if err = rd.Set(attrFileOwner, f.permission.owner); err != nil {
+			return
+		}
+	}
+	if f.permission.group != idUnmanaged {
+		if err = rd.Set(attrFileGroup, f.permission.group); err != nil {
+			return
+		}
 	}
-	if err = rd.Set(attrFileGroup, f.permission.group); err != nil {
+	if err = rd.Set(attrFileOwnerName, f.permission.ownerName); err != nil {
 		return
 	}
-	if err = rd.Set(attrFileMode, f.permission.mode); err != nil {
+	if err = rd.Set(attrFileGroupName, f.permission.groupName); err != nil {
 		return
 	}
+	if f.permission.mode != "" {
+		if err = rd.Set(attrFileMode, f.permission.mode); err != nil {
+			return
+		}
+	}
 	if err = rd.Set(attrFileOverwrite, f.overwrite); err != nil {
 		return
 	}
-	if err = rd.Set(attrFileRecyclePath, f.recyclePath); err != nil {
+	if err = rd.Set(attrFileRecyclePath, f.recycle.path); err != nil {
+		return
+	}
+	if err = rd.Set(attrFileRecycleRetention, f.recycle.retention); err != nil {
+		return
+	}
+	if err = rd.Set(attrFileRecycleMaxItems, f.recycle.maxItems); err != nil {
+		return
+	}
+	if err = rd.Set(attrFileDeleteStrategy, f.deleteStrategy); err != nil {
+		return
+	}
+	if err = rd.Set(attrFileRestoreFromRecycle, f.restore); err != nil {
+		return
+	}
+	if err = rd.Set(attrFileOnCreate, f.hook.onCreate); err != nil {
+		return
+	}
+	if err = rd.Set(attrFileOnChange, f.hook.onChange); err != nil {
+		return
+	}
+	if err = rd.Set(attrFileOnDestroy, f.hook.onDestroy); err != nil {
+		return
+	}
+	if err = rd.Set(attrFileHookWorkdir, f.hook.workdir); err != nil {
+		return
+	}
+	if err = rd.Set(attrFileHookEnvironment, f.hook.env); err != nil {
+		return
+	}
+	if err = rd.Set(attrFileBackup, f.backup); err != nil {
+		return
+	}
+	if err = rd.Set(attrFileBackupPath, f.backupPath); err != nil {
 		return
 	}
+	if err = rd.Set(attrFileBackupLocation, f.backupLocation); err != nil {
+		return
+	}
+	if err = rd.Set(attrFileBackupLocations, f.backupLocations); err != nil {
+		return
+	}
+	if err = rd.Set(attrFileProtectModified, f.protectModified); err != nil {
+		return
+	}
+	if err = rd.Set(attrFileForce, f.force); err != nil {
+		return
+	}
+	if err = rd.Set(attrFileAppliedSHA256, f.appliedSHA256); err != nil {
+		return
+	}
+	if err = rd.Set(attrFileAppliedSalt, f.appliedSalt); err != nil {
+		return
+	}
+	if err = rd.Set(attrFileContentDiffRedact, f.contentDiffRedact); err != nil {
+		return
+	}
+	if f.attributes.selinuxContext != "" {
+		if err = rd.Set(attrFileSelinuxContext, f.attributes.selinuxContext); err != nil {
+			return
+		}
+	}
+	if f.attributes.acl != nil {
+		if err = rd.Set(attrFileACL, f.attributes.acl); err != nil {
+			return
+		}
+	}
+	if f.attributes.xattrs != nil {
+		if err = rd.Set(attrFileXattrs, f.attributes.xattrs); err != nil {
+			return
+		}
+	}
 
 	if err = rd.Set(attrFileIgnoreContent, f.ignoreContent); err != nil {
 		return
 	}
-	if f.ignoreContent {
+	if err = rd.Set(attrFileContentWOVersion, f.contentWOVersion); err != nil {
 		return
 	}
+	if f.ignoreContent || f.writeOnly() {
+		return // content is never stored in state
+	}
 	if err = rd.Set(attrFileContent, f.content); err != nil {
 		return
 	}
@@ -177,13 +587,41 @@ func (h handlerFileResource) Read(ctx context.Context, rd *schema.ResourceData,
 	if err != nil {
 		return diag.FromErr(err)
 	}
-	f, err := l.readFile(ctx, cast.ToString(rd.Get(attrFilePath)), cast.ToBool(rd.Get(attrFileIgnoreContent)))
-	if err != nil && !errors.Is(err, errPathNotExist) {
+	ignoreContent, woVersion := cast.ToBool(rd.Get(attrFileIgnoreContent)), cast.ToInt(rd.Get(attrFileContentWOVersion))
+	f, err := l.readFile(ctx, cast.ToString(rd.Get(attrFilePath)), ignoreContent || woVersion != 0)
+	if errors.Is(err, errPathNotExist) {
+		rd.SetId("")
+		return
+	}
+	if err != nil {
 		return diag.FromErr(err)
 	}
+	f.ignoreContent, f.contentWOVersion = ignoreContent, woVersion
+	if f.writeOnly() {
+		sum, err := l.sha256sum(ctx, f.path, cast.ToString(rd.Get(attrFileAppliedSalt)))
+		if err != nil && !errors.Is(err, errPathNotExist) {
+			return diag.FromErr(err)
+		}
+		if sum != cast.ToString(rd.Get(attrFileAppliedSHA256)) {
+			f.contentWOVersion = -1 // drifted, will be written again
+		}
+	}
 
+	f.attributes.acl = aclAsConfigured(rd.Get(attrFileACL), f.attributes.acl)
 	f.overwrite = cast.ToBool(rd.Get(attrFileOverwrite))
-	f.recyclePath = cast.ToString(rd.Get(attrFileRecyclePath))
+	f.recycle = newRecycleBin(rd, attrFileRecyclePath, attrFileRecycleRetention, attrFileRecycleMaxItems)
+	f.deleteStrategy = cast.ToString(rd.Get(attrFileDeleteStrategy))
+	f.restore = cast.ToBool(rd.Get(attrFileRestoreFromRecycle))
+	f.hook = h.newHook(rd)
+	f.backup = cast.ToBool(rd.Get(attrFileBackup))
+	f.backupPath = cast.ToString(rd.Get(attrFileBackupPath))
+	f.backupLocation = cast.ToString(rd.Get(attrFileBackupLocation))
+	f.backupLocations = cast.ToStringSlice(rd.Get(attrFileBackupLocations))
+	f.protectModified = cast.ToBool(rd.Get(attrFileProtectModified))
+	f.force = cast.ToBool(rd.Get(attrFileForce))
+	f.appliedSHA256 = cast.ToString(rd.Get(attrFileAppliedSHA256))
+	f.appliedSalt = cast.ToString(rd.Get(attrFileAppliedSalt))
+	f.contentDiffRedact = cast.ToStringSlice(rd.Get(attrFileContentDiffRedact))
 	if err = h.updateResourceData(f, rd); err != nil {
 		diag.FromErr(err)
 	}
@@ -196,7 +634,23 @@ func (h handlerFileResource) Create(ctx context.Context, rd *schema.ResourceData
 		return diag.FromErr(err)
 	}
 	f := h.newFile(rd)
-	if err := l.createFile(ctx, f); err != nil {
+	errCreate := l.createFile(ctx, f)
+	if errCreate != nil && !errors.Is(errCreate, errHook) {
+		return diag.FromErr(errCreate)
+	}
+	if err := rd.Set(attrFileBackupLocation, f.backupLocation); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := rd.Set(attrFileBackupLocations, f.backupLocations); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := rd.Set(attrFileAppliedSHA256, f.appliedSHA256); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := rd.Set(attrFileAppliedSalt, f.appliedSalt); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := rd.Set(attrFileContentDiff, ""); err != nil {
 		return diag.FromErr(err)
 	}
 
@@ -206,6 +660,9 @@ func (h handlerFileResource) Create(ctx context.Context, rd *schema.ResourceData
 	}
 
 	rd.SetId(id.String())
+	if errCreate != nil { // the file has been written, so let the failed hook taint it
+		return append(diag.FromErr(errCreate), h.Read(ctx, rd, meta)...)
+	}
 	return h.Read(ctx, rd, meta)
 }
 
@@ -216,11 +673,39 @@ func (h handlerFileResource) Update(ctx context.Context, rd *schema.ResourceData
 	}
 
 	old, new := h.newDiffedFile(rd)
+	diffUnknown := h.contentDiffUnknown(rd) // content wasn't known during plan
+	var current string
+	if diffUnknown {
+		if current, err = l.remoteContent(ctx, old.path); err != nil {
+			return diag.FromErr(err)
+		}
+	}
 	err = l.updateFile(ctx, old, new)
 	if err != nil {
 		_ = h.updateResourceData(old, rd) // WARN: see https://github.com/hashicorp/terraform-plugin-sdk/issues/476
 		return diag.FromErr(err)
 	}
+	if err = rd.Set(attrFileBackupLocation, new.backupLocation); err != nil {
+		return diag.FromErr(err)
+	}
+	if err = rd.Set(attrFileBackupLocations, new.backupLocations); err != nil {
+		return diag.FromErr(err)
+	}
+	if err = rd.Set(attrFileAppliedSHA256, new.appliedSHA256); err != nil {
+		return diag.FromErr(err)
+	}
+	if err = rd.Set(attrFileAppliedSalt, new.appliedSalt); err != nil {
+		return diag.FromErr(err)
+	}
+	if diffUnknown {
+		diff, err := h.contentDiff(new.path, current, new.content, new.diffRedact())
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		if err = rd.Set(attrFileContentDiff, diff); err != nil {
+			return diag.FromErr(err)
+		}
+	}
 
 	return h.Read(ctx, rd, meta)
 }
@@ -237,6 +722,81 @@ func (h handlerFileResource) Delete(ctx context.Context, rd *schema.ResourceData
 	return
 }
 
+func (h handlerFileResource) Import(ctx context.Context, rd *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	if err := importPath(meta.(*linuxPool), rd, schemaFileResource, attrFileProviderOverride, attrFilePath); err != nil {
+		return nil, err
+	}
+	return []*schema.ResourceData{rd}, nil
+}
+
+// contentWO returns the write-only content, which is only available from the configuration during plan and apply.
+func (handlerFileResource) contentWO(rd *schema.ResourceData) string {
+	v, _ := rd.GetRawConfigAt(cty.GetAttrPath(attrFileContentWO))
+	if !v.IsKnown() || v.IsNull() || !v.Type().Equals(cty.String) {
+		return ""
+	}
+	return v.AsString()
+}
+
+func (h handlerFileResource) CustomizeDiff(ctx context.Context, rd *schema.ResourceDiff, meta interface{}) (err error) {
+	if err = validateDeleteStrategy(rd, attrFileDeleteStrategy, attrFileRecyclePath); err != nil {
+		return
+	}
+	if cast.ToBool(rd.Get(attrFileRestoreFromRecycle)) && rd.NewValueKnown(attrFileIgnoreContent) &&
+		!cast.ToBool(rd.Get(attrFileIgnoreContent)) {
+		return fmt.Errorf("'%s' is required when '%s' is true, otherwise the restored content is overwritten",
+			attrFileIgnoreContent, attrFileRestoreFromRecycle)
+	}
+	return h.customizeContentDiff(ctx, rd, meta)
+}
+
+// customizeContentDiff shows the unified diff of the remote and the desired content in the plan of an update.
+func (h handlerFileResource) customizeContentDiff(ctx context.Context, rd *schema.ResourceDiff, meta interface{}) (err error) {
+	if rd.Id() == "" {
+		return rd.SetNew(attrFileContentDiff, "")
+	}
+	if cast.ToBool(rd.Get(attrFileIgnoreContent)) || cast.ToInt(rd.Get(attrFileContentWOVersion)) != 0 ||
+		!rd.HasChange(attrFileContent) {
+		return
+	}
+	if !rd.NewValueKnown(attrFileContent) || !rd.NewValueKnown(attrFileContentDiffRedact) ||
+		!rd.NewValueKnown(attrFileProviderOverride) {
+		return rd.SetNewComputed(attrFileContentDiff)
+	}
+	l, err := getLinux(meta.(*linuxPool), rd)
+	if err != nil {
+		return
+	}
+	o, _ := rd.GetChange(attrFilePath)
+	current, err := l.remoteContent(ctx, cast.ToString(o))
+	if err != nil {
+		return
+	}
+	diff, err := h.contentDiff(cast.ToString(rd.Get(attrFilePath)), current, cast.ToString(rd.Get(attrFileContent)),
+		cast.ToStringSlice(rd.Get(attrFileContentDiffRedact)))
+	if err != nil {
+		return
+	}
+	return rd.SetNew(attrFileContentDiff, diff)
+}
+
+func (handlerFileResource) contentDiff(path, old, new string, redact []string) (diff string, err error) {
+	patterns, err := compileRedactPatterns(redact)
+	if err != nil {
+		return
+	}
+	return redactDiff(unifiedDiff(path, path+" (planned)", old, new), patterns), nil
+}
+
+// contentDiffUnknown reports whether content_diff is planned as unknown and has to be computed during apply.
+func (handlerFileResource) contentDiffUnknown(rd *schema.ResourceData) bool {
+	plan := rd.GetRawPlan()
+	if plan.IsNull() || !plan.IsKnown() || !plan.Type().HasAttribute(attrFileContentDiff) {
+		return false
+	}
+	return !plan.GetAttr(attrFileContentDiff).IsKnown()
+}
+
 func fileResource() *schema.Resource {
 	var hfr handlerFileResource
 	return &schema.Resource{
@@ -245,5 +805,9 @@ func fileResource() *schema.Resource {
 		ReadContext:   hfr.Read,
 		UpdateContext: hfr.Update,
 		DeleteContext: hfr.Delete,
+		CustomizeDiff: hfr.CustomizeDiff,
+		Importer: &schema.ResourceImporter{
+			StateContext: hfr.Import,
+		},
 	}
 }
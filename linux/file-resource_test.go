@@ -1,11 +1,14 @@
 package linux
 
 import (
+	"fmt"
 	"regexp"
 	"testing"
 
 	"github.com/MakeNowJust/heredoc"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 	"github.com/stretchr/testify/require"
 )
 
@@ -22,6 +25,16 @@ func TestAccLinuxFileBasic(t *testing.T) {
 		File:     tNewTFMapFile(),
 		Extra:    tfmap{"path_previous": conf1.File[attrFilePath]},
 	}
+	conf4 := conf3.Copy(func(tc *tfConf) {
+		tc.File = tc.File.Without(attrFileOwner, attrFileGroup).
+			With(attrFileOwnerName, `"root"`).
+			With(attrFileGroupName, `"root"`)
+		tc.Extra = tfmap{}
+	})
+	conf5 := conf4.Copy(func(tc *tfConf) {
+		tc.File.With(attrFileMode, `"u=rwx,g=rx,o="`)
+		tc.Extra.With("mode", `"750"`)
+	})
 
 	resource.Test(t, resource.TestCase{
 		ExternalProviders: map[string]resource.ExternalProvider{"null": {}},
@@ -37,6 +50,12 @@ func TestAccLinuxFileBasic(t *testing.T) {
 			{
 				Config: testAccLinuxFileBasicConfig(t, conf3),
 			},
+			{
+				Config: testAccLinuxFileBasicConfig(t, conf4),
+			},
+			{
+				Config: testAccLinuxFileBasicConfig(t, conf5),
+			},
 		},
 	})
 }
@@ -95,7 +114,7 @@ func testAccLinuxFileBasicConfig(t *testing.T, conf tfConf) (s string) {
 		                cmp -s "${self.triggers["path"]}" "${self.triggers["path_compare"]}" || exit 102
 		                [ "$( stat -c %u '${self.triggers["path"]}' )" == "{{ .File.owner | default 0 }}" ] || exit 103
 		                [ "$( stat -c %g '${self.triggers["path"]}' )" == "{{ .File.group | default 0 }}" ] || exit 104
-		                [ "$( stat -c %a '${self.triggers["path"]}' )" == {{ .File.mode | default 644 }} ] || exit 105
+		                [ "$( stat -c %a '${self.triggers["path"]}' )" == {{ .Extra.mode | default .File.mode | default 644 }} ] || exit 105
 		            EOF
 		        ]
 		    }
@@ -112,6 +131,45 @@ func testAccLinuxFileBasicConfig(t *testing.T, conf tfConf) (s string) {
 	return
 }
 
+func TestAccLinuxFileImport(t *testing.T) {
+	conf := tfConf{
+		Provider: testAccProvider,
+		File:     tNewTFMapFile(),
+	}
+
+	var created map[string]string
+	resource.Test(t, resource.TestCase{
+		ExternalProviders: map[string]resource.ExternalProvider{"null": {}},
+		PreCheck:          testAccPreCheckConnection(t),
+		Providers:         testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccLinuxFileBasicConfig(t, conf),
+			},
+			{
+				Config:       testAccLinuxFileBasicConfig(t, conf),
+				ResourceName: "linux_file.file",
+				ImportState:  true,
+				ImportStateIdFunc: func(s *terraform.State) (string, error) {
+					created = s.RootModule().Resources["linux_file.file"].Primary.Attributes
+					return created[attrFilePath], nil
+				},
+				ImportStateCheck: func(is []*terraform.InstanceState) error {
+					if len(is) != 1 {
+						return fmt.Errorf("expected 1 imported state, got %d", len(is))
+					}
+					for _, k := range []string{attrFilePath, attrFileContent, attrFileOwner, attrFileGroup, attrFileMode} {
+						if is[0].Attributes[k] != created[k] {
+							return fmt.Errorf("imported %s is %q, expected %q", k, is[0].Attributes[k], created[k])
+						}
+					}
+					return nil
+				},
+			},
+		},
+	})
+}
+
 func TestAccLinuxFileOverride(t *testing.T) {
 	conf1 := tfConf{
 		Provider: testAccProvider,
@@ -410,3 +468,542 @@ func testAccLinuxFileRecyclePathConfig(t *testing.T, conf tfConf) (s string) {
 	require.NoError(t, err, "compile template failed")
 	return
 }
+
+func TestAccLinuxFileDeleteStrategy(t *testing.T) {
+	conf1 := tfConf{
+		Provider: testAccProvider,
+		File:     tNewTFMapFile().With(attrFileDeleteStrategy, `"retain"`),
+		Extra:    tfmap{"exist": "true"},
+	}
+	conf2 := tfConf{
+		Provider: testAccProvider,
+		File:     tNewTFMapFile().With(attrFileDeleteStrategy, `"shred"`),
+		Extra:    tfmap{"exist": "false"},
+	}
+	resource.Test(t, resource.TestCase{
+		ExternalProviders: map[string]resource.ExternalProvider{"null": {}},
+		PreCheck:          testAccPreCheckConnection(t),
+		Providers:         testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccLinuxFileDeleteStrategyConfig(t, conf1),
+			},
+		},
+	})
+	resource.Test(t, resource.TestCase{
+		ExternalProviders: map[string]resource.ExternalProvider{"null": {}},
+		PreCheck:          testAccPreCheckConnection(t),
+		Providers:         testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccLinuxFileDeleteStrategyConfig(t, conf2),
+			},
+		},
+	})
+}
+
+func testAccLinuxFileDeleteStrategyConfig(t *testing.T, conf tfConf) (s string) {
+	tf := heredoc.Doc(`
+		provider "linux" {
+			alias = "test"
+		    {{- .Provider.Serialize | nindent 4 }}
+		}
+
+		resource "null_resource" "destroy_checker" {
+		    triggers = {
+		        path = {{ .File.path }}
+		        exist = {{ .Extra.exist }}
+		    }
+		    connection {
+		        type = "ssh"
+		        {{- .Provider.Serialize | nindent 8 }}
+		    }
+		    provisioner "remote-exec" {
+		        when = destroy
+		        inline = [
+		            <<-EOF
+		                if ${self.triggers["exist"]}; then
+		                    [ -f '${self.triggers["path"]}' ] || exit 100
+		                    rm -f '${self.triggers["path"]}'
+		                else
+		                    [ ! -e '${self.triggers["path"]}' ] || exit 101
+		                fi
+		            EOF
+		        ]
+		    }
+		}
+
+		resource "linux_file" "file" {
+			provider = "linux.test"
+		    depends_on = [ null_resource.destroy_checker ]
+		    {{- .File.Serialize | nindent 4 }}
+		}
+	`)
+	s, err := conf.compile(tf)
+	t.Log(s)
+	require.NoError(t, err, "compile template failed")
+	return
+}
+
+func TestAccLinuxFileProtectModified(t *testing.T) {
+	conf1 := tfConf{
+		Provider: testAccProvider,
+		File:     tNewTFMapFile().With(attrFileProtectModified, "true"),
+	}
+	conf2 := conf1.Copy(func(tc *tfConf) {
+		tc.File[attrFileContent] = `"updated"`
+	})
+	conf3 := conf2.Copy(func(tc *tfConf) {
+		tc.File[attrFileForce] = "true"
+	})
+	resource.Test(t, resource.TestCase{
+		ExternalProviders: map[string]resource.ExternalProvider{"null": {}},
+		PreCheck:          testAccPreCheckConnection(t),
+		Providers:         testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config:             testAccLinuxFileProtectModifiedConfig(t, conf1),
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				Config:      testAccLinuxFileProtectModifiedConfig(t, conf2),
+				ExpectError: regexp.MustCompile("modified outside of terraform"),
+			},
+			{
+				Config: testAccLinuxFileProtectModifiedConfig(t, conf3),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("linux_file.file", attrFileContent, "updated"),
+					resource.TestCheckResourceAttr("linux_file.file", attrFileAppliedSHA256,
+						"27eb5e51506c911f6fc4bb345c0d9db6f60415fceab7c18e1e9b862637415777"),
+				),
+			},
+		},
+	})
+}
+
+func testAccLinuxFileProtectModifiedConfig(t *testing.T, conf tfConf) (s string) {
+	tf := heredoc.Doc(`
+		provider "linux" {
+			alias = "test"
+		    {{- .Provider.Serialize | nindent 4 }}
+		}
+
+		resource "linux_file" "file" {
+			provider = "linux.test"
+		    {{- .File.Serialize | nindent 4 }}
+		}
+
+		resource "null_resource" "modifier" {
+		    triggers = {
+		        path = linux_file.file.path
+		    }
+		    connection {
+		        type = "ssh"
+		        {{- .Provider.Serialize | nindent 8 }}
+		    }
+		    provisioner "remote-exec" {
+		        inline = [ "echo modified >> '${self.triggers["path"]}'" ]
+		    }
+		}
+	`)
+	s, err := conf.compile(tf)
+	t.Log(s)
+	require.NoError(t, err, "compile template failed")
+	return
+}
+
+func TestAccLinuxFileContentDiff(t *testing.T) {
+	conf1 := tfConf{
+		Provider: testAccProvider,
+		File: tNewTFMapFile().
+			With(attrFileContent, `"user=admin\npassword=first\n"`).
+			With(attrFileContentDiffRedact, `["^password="]`),
+	}
+	conf2 := conf1.Copy(func(tc *tfConf) {
+		tc.File[attrFileContent] = `"user=admin\npassword=second\n"`
+	})
+	resource.Test(t, resource.TestCase{
+		PreCheck:  testAccPreCheckConnection(t),
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccLinuxFileContentDiffConfig(t, conf1),
+				Check:  resource.TestCheckResourceAttr("linux_file.file", attrFileContentDiff, ""),
+			},
+			{
+				Config: testAccLinuxFileContentDiffConfig(t, conf2),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("linux_file.file", attrFileContent, "user=admin\npassword=second\n"),
+					resource.TestMatchResourceAttr("linux_file.file", attrFileContentDiff,
+						regexp.MustCompile(`\n@@ -1,2 \+1,2 @@\n user=admin\n-\(redacted\)\n\+\(redacted\)\n$`)),
+				),
+			},
+		},
+	})
+}
+
+func testAccLinuxFileContentDiffConfig(t *testing.T, conf tfConf) (s string) {
+	tf := heredoc.Doc(`
+		provider "linux" {
+			alias = "test"
+		    {{- .Provider.Serialize | nindent 4 }}
+		}
+
+		resource "linux_file" "file" {
+			provider = "linux.test"
+		    {{- .File.Serialize | nindent 4 }}
+		}
+	`)
+	s, err := conf.compile(tf)
+	t.Log(s)
+	require.NoError(t, err, "compile template failed")
+	return
+}
+
+func TestAccLinuxFileContentWO(t *testing.T) {
+	conf1 := tfConf{
+		Provider: testAccProvider,
+		File: tNewTFMapFile().Without(attrFileContent).
+			With(attrFileContentWO, `"secret"`).
+			With(attrFileContentWOVersion, "1"),
+	}
+	conf2 := conf1.Copy(func(tc *tfConf) {
+		tc.File.With(attrFileContentWO, `"secret2"`).With(attrFileContentWOVersion, "2")
+	})
+	resource.Test(t, resource.TestCase{
+		ExternalProviders: map[string]resource.ExternalProvider{"null": {}},
+		PreCheck:          testAccPreCheckConnection(t),
+		Providers:         testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccLinuxFileContentWOConfig(t, conf1),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckNoResourceAttr("linux_file.file", attrFileContentWO),
+					resource.TestCheckResourceAttr("linux_file.file", attrFileContent, ""),
+					resource.TestMatchResourceAttr("linux_file.file", attrFileAppliedSalt, regexp.MustCompile(`^[0-9a-f]{32}$`)),
+					resource.TestCheckResourceAttrWith("linux_file.file", attrFileAppliedSHA256, func(v string) error {
+						if v == "2bb80d537b1da3e38bd30361aa855686bde0eacd7162fef6a25fe97bf527a25b" { // unsalted
+							return fmt.Errorf("%s reveals the checksum of %s", attrFileAppliedSHA256, attrFileContentWO)
+						}
+						return nil
+					}),
+				),
+			},
+			{
+				Config: testAccLinuxFileContentWOConfig(t, conf2),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckNoResourceAttr("linux_file.file", attrFileContentWO),
+					resource.TestCheckResourceAttr("linux_file.file", attrFileContent, ""),
+					resource.TestMatchResourceAttr("linux_file.file", attrFileAppliedSalt, regexp.MustCompile(`^[0-9a-f]{32}$`)),
+					resource.TestCheckResourceAttrWith("linux_file.file", attrFileAppliedSHA256, func(v string) error {
+						if v == "35224d0d3465d74e855f8d69a136e79c744ea35a675d3393360a327cbf6359a2" { // unsalted
+							return fmt.Errorf("%s reveals the checksum of %s", attrFileAppliedSHA256, attrFileContentWO)
+						}
+						return nil
+					}),
+				),
+			},
+		},
+	})
+}
+
+func testAccLinuxFileContentWOConfig(t *testing.T, conf tfConf) (s string) {
+	tf := heredoc.Doc(`
+		provider "linux" {
+			alias = "test"
+		    {{- .Provider.Serialize | nindent 4 }}
+		}
+
+		resource "linux_file" "file" {
+			provider = "linux.test"
+		    {{- .File.Serialize | nindent 4 }}
+		}
+
+		resource "null_resource" "validator" {
+		    triggers = {
+		        path    = linux_file.file.path
+		        version = linux_file.file.content_wo_version
+		    }
+		    connection {
+		        type = "ssh"
+		        {{- .Provider.Serialize | nindent 8 }}
+		    }
+		    provisioner "remote-exec" {
+		        inline = [
+		            <<-EOF
+		                [ "$(cat '${self.triggers["path"]}')" == {{ .File.content_wo }} ] || exit 101
+		            EOF
+		        ]
+		    }
+		}
+	`)
+	s, err := conf.compile(tf)
+	t.Log(s)
+	require.NoError(t, err, "compile template failed")
+	return
+}
+
+func TestAccLinuxFileHook(t *testing.T) {
+	marker := fmt.Sprintf(`"/tmp/linux/%s.hook"`, acctest.RandString(16))
+	conf1 := tfConf{
+		Provider: testAccProvider,
+		File: tNewTFMapFile().
+			With(attrFileOnCreate, `"echo create >> $MARKER"`).
+			With(attrFileOnChange, `"echo change >> $MARKER"`).
+			With(attrFileOnDestroy, `"echo destroy >> $MARKER"`),
+		Extra: tfmap{"marker": marker, "expected": `"create"`},
+	}
+	conf2 := conf1.Copy(func(tc *tfConf) {
+		tc.File.With(attrFileContent, `"test"`)
+		tc.Extra.With("expected", `"create change"`)
+	})
+	conf3 := conf2.Copy(func(tc *tfConf) {
+		tc.File.With(attrFileOverwrite, "true")
+	})
+
+	resource.Test(t, resource.TestCase{
+		ExternalProviders: map[string]resource.ExternalProvider{"null": {}},
+		PreCheck:          testAccPreCheckConnection(t),
+		Providers:         testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccLinuxFileHookConfig(t, conf1),
+			},
+			{
+				Config: testAccLinuxFileHookConfig(t, conf2),
+			},
+			{
+				Config: testAccLinuxFileHookConfig(t, conf3),
+			},
+		},
+	})
+}
+
+func TestAccLinuxFileHookFailed(t *testing.T) {
+	conf1 := tfConf{
+		Provider: testAccProvider,
+		File:     tNewTFMapFile().With(attrFileOnCreate, `"exit 1"`),
+	}
+	conf2 := conf1.Copy(func(tc *tfConf) {
+		tc.File.With(attrFileOnCreate, `"true"`)
+	})
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  testAccPreCheckConnection(t),
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccLinuxFileHookFailedConfig(t, conf1),
+				ExpectError: regexp.MustCompile("while running hook"),
+			},
+			{
+				Config: testAccLinuxFileHookFailedConfig(t, conf2), // replaces the tainted file instead of failing on existing path
+			},
+		},
+	})
+}
+
+func testAccLinuxFileHookFailedConfig(t *testing.T, conf tfConf) (s string) {
+	tf := heredoc.Doc(`
+		provider "linux" {
+			alias = "test"
+		    {{- .Provider.Serialize | nindent 4 }}
+		}
+
+		resource "linux_file" "file" {
+			provider = "linux.test"
+		    {{- .File.Serialize | nindent 4 }}
+		}
+	`)
+	s, err := conf.compile(tf)
+	t.Log(s)
+	require.NoError(t, err, "compile template failed")
+	return
+}
+
+func testAccLinuxFileHookConfig(t *testing.T, conf tfConf) (s string) {
+	s, err := conf.compile(tHookConfig("linux_file.file", "{{- .File.Serialize | nindent 4 }}", attrFileContent, attrFileOverwrite))
+	t.Log(s)
+	require.NoError(t, err, "compile template failed")
+	return
+}
+
+func TestAccLinuxFileBackup(t *testing.T) {
+	conf1 := tfConf{
+		Provider: testAccProvider,
+		File: tNewTFMapFile().
+			With(attrFileContent, `"first"`).
+			With(attrFileOverwrite, "true").
+			With(attrFileBackupPath, fmt.Sprintf(`"/tmp/linux/backup-%s"`, acctest.RandString(16))),
+		Extra: tfmap{"previous": `"existing"`},
+	}
+	conf2 := conf1.Copy(func(tc *tfConf) {
+		tc.File.With(attrFileContent, `"second"`)
+		tc.Extra.With("previous", `"first"`)
+	})
+
+	resource.Test(t, resource.TestCase{
+		ExternalProviders: map[string]resource.ExternalProvider{"null": {}},
+		PreCheck:          testAccPreCheckConnection(t),
+		Providers:         testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccLinuxFileBackupConfig(t, conf1),
+			},
+			{
+				Config: testAccLinuxFileBackupConfig(t, conf2),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("linux_file.file", attrFileBackupLocations+".#", "1"),
+					resource.TestCheckResourceAttrPair("linux_file.file", attrFileBackupLocations+".0",
+						"linux_file.file", attrFileBackupLocation),
+				),
+			},
+		},
+	})
+}
+
+func testAccLinuxFileBackupConfig(t *testing.T, conf tfConf) (s string) {
+	tf := heredoc.Doc(`
+		provider "linux" {
+			alias = "test"
+		    {{- .Provider.Serialize | nindent 4 }}
+		}
+
+		resource "null_resource" "existing_file" {
+		    triggers = {
+		        path = {{ .File.path }}
+		        backup_path = {{ .File.backup_path }}
+		    }
+		    connection {
+		        type = "ssh"
+		        {{- .Provider.Serialize | nindent 8 }}
+		    }
+		    provisioner "remote-exec" {
+		        inline = [ "mkdir -p ${ dirname(self.triggers["path"]) }" ]
+		    }
+		    provisioner "file" {
+		        content = "existing"
+		        destination = self.triggers["path"]
+		    }
+		    provisioner "remote-exec" {
+		        when = destroy
+		        inline = [ "rm -rf ${self.triggers["backup_path"]}" ]
+		    }
+		}
+
+		resource "linux_file" "file" {
+			provider = "linux.test"
+		    depends_on = [ null_resource.existing_file ]
+
+		    {{- .File.Serialize | nindent 4 }}
+		}
+
+		resource "null_resource" "create_validator" {
+		    triggers = {
+		        backup_location = linux_file.file.backup_location
+		        previous = {{ .Extra.previous }}
+		        path_compare = "${linux_file.file.path}.compare"
+		    }
+		    connection {
+		        type = "ssh"
+		        {{- .Provider.Serialize | nindent 8 }}
+		    }
+		    provisioner "file" {
+		        content = self.triggers["previous"]
+		        destination = self.triggers["path_compare"]
+		    }
+		    provisioner "remote-exec" {
+		        inline = [
+		            <<-EOF
+		                [ "$(dirname '${self.triggers["backup_location"]}')" == {{ .File.backup_path }} ] || exit 101
+		                cmp -s "${self.triggers["backup_location"]}" "${self.triggers["path_compare"]}" || exit 102
+		            EOF
+		        ]
+		    }
+		    provisioner "remote-exec" {
+		        when = destroy
+		        inline = [ "rm -f '${self.triggers["path_compare"]}'" ]
+		    }
+		}
+	`)
+
+	s, err := conf.compile(tf)
+	t.Log(s)
+	require.NoError(t, err, "compile template failed")
+	return
+}
+
+func TestAccLinuxFileAttributes(t *testing.T) {
+	conf0 := tfConf{
+		Provider: testAccProvider,
+		File:     tNewTFMapFile().With(attrFileXattrs, `{ "security.capability" = "" }`),
+		Extra:    tfmap{"expected": `""`, "removed": `"user.none"`},
+	}
+	conf1 := tfConf{
+		Provider: testAccProvider,
+		File:     tNewTFMapFile().With(attrFileXattrs, `{ "user.comment" = "first", "user.extra" = "extra" }`),
+		Extra:    tfmap{"expected": `"first"`, "removed": `"user.none"`},
+	}
+	conf2 := conf1.Copy(func(tc *tfConf) {
+		tc.File.With(attrFileXattrs, `{ "user.comment" = "second" }`)
+		tc.Extra.With("expected", `"second"`).With("removed", `"user.extra"`)
+	})
+
+	resource.Test(t, resource.TestCase{
+		ExternalProviders: map[string]resource.ExternalProvider{"null": {}},
+		PreCheck:          testAccPreCheckConnection(t),
+		Providers:         testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccLinuxFileAttributesConfig(t, conf0),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile("must be in the `user.` namespace"),
+			},
+			{
+				Config: testAccLinuxFileAttributesConfig(t, conf1),
+			},
+			{
+				Config: testAccLinuxFileAttributesConfig(t, conf2),
+			},
+		},
+	})
+}
+
+func testAccLinuxFileAttributesConfig(t *testing.T, conf tfConf) (s string) {
+	tf := heredoc.Doc(`
+		provider "linux" {
+			alias = "test"
+		    {{- .Provider.Serialize | nindent 4 }}
+		}
+
+		resource "linux_file" "file" {
+			provider = "linux.test"
+
+		    {{- .File.Serialize | nindent 4 }}
+		}
+
+		resource "null_resource" "create_validator" {
+		    triggers = {
+		        path = linux_file.file.path
+		        xattrs = jsonencode(linux_file.file.xattrs)
+		    }
+		    connection {
+		        type = "ssh"
+		        {{- .Provider.Serialize | nindent 8 }}
+		    }
+		    provisioner "remote-exec" {
+		        inline = [
+		            <<-EOF
+		                [ "$(getfattr --only-values -n user.comment '${self.triggers["path"]}')" == {{ .Extra.expected }} ] || exit 101
+		                ! getfattr -n {{ .Extra.removed }} '${self.triggers["path"]}' || exit 102
+		            EOF
+		        ]
+		    }
+		}
+	`)
+
+	s, err := conf.compile(tf)
+	t.Log(s)
+	require.NoError(t, err, "compile template failed")
+	return
+}
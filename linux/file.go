@@ -1,6 +1,11 @@
 package linux
 
 import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"path/filepath"
 	"strings"
@@ -14,10 +19,56 @@ type file struct {
 	path       string
 	content    string
 	permission permission
+	attributes attributes
 
-	ignoreContent bool
-	overwrite     bool
-	recyclePath   string
+	ignoreContent    bool
+	contentWOVersion int
+	overwrite        bool
+	recycle          recycleBin
+	restore          bool
+	deleteStrategy   string
+
+	backup          bool
+	backupPath      string
+	backupLocation  string
+	backupLocations []string
+
+	protectModified bool
+	force           bool
+	appliedSHA256   string
+	appliedSalt     string
+
+	contentDiffRedact []string
+
+	hook hook
+}
+
+func (f *file) backupEnabled() bool {
+	return f.backup || f.backupPath != ""
+}
+
+// sha256 returns the checksum of content prefixed with appliedSalt, which is only set for write-only content.
+func (f *file) sha256() string {
+	sum := sha256.Sum256([]byte(f.appliedSalt + f.content))
+	return hex.EncodeToString(sum[:])
+}
+
+// writeOnly reports whether content comes from a write-only attribute and must never be stored in state.
+func (f *file) writeOnly() bool {
+	return f.contentWOVersion != 0
+}
+
+// diffRedact returns the patterns for redacting diffs of f. Write-only content is never revealed.
+func (f *file) diffRedact() []string {
+	if f.writeOnly() {
+		return []string{""}
+	}
+	return f.contentDiffRedact
+}
+
+// protected reports whether modifications made outside of terraform should prevent f from being discarded.
+func (f *file) protected() bool {
+	return f.protectModified && !f.force
 }
 
 func (l *linux) readFile(ctx context.Context, path string, ignoreContent bool) (f *file, err error) {
@@ -26,7 +77,12 @@ func (l *linux) readFile(ctx context.Context, path string, ignoreContent bool) (
 		return
 	}
 
-	f = &file{path: path, permission: perm, ignoreContent: ignoreContent}
+	attrs, err := l.getAttributes(ctx, path)
+	if err != nil {
+		return
+	}
+
+	f = &file{path: path, permission: perm, attributes: attrs, ignoreContent: ignoreContent}
 	if f.ignoreContent {
 		return
 	}
@@ -35,11 +91,43 @@ func (l *linux) readFile(ctx context.Context, path string, ignoreContent bool) (
 	return
 }
 
+// remoteContent returns the content of the regular file at path, or an empty string when there is none.
+func (l *linux) remoteContent(ctx context.Context, path string) (s string, err error) {
+	stdout := new(bytes.Buffer)
+	pathSafe := shellescape.Quote(path)
+	cmd := fmt.Sprintf(`{ [ ! -f %s ] || cat -- %s ;}`, pathSafe, pathSafe)
+	if err = l.exec(ctx, &remote.Cmd{Command: cmd, Stdout: stdout}); err != nil {
+		return
+	}
+	return stdout.String(), nil
+}
+
 func (l *linux) createFile(ctx context.Context, f *file) (err error) {
 	if f == nil {
 		return errNil
 	}
+	if f.restore {
+		restored, err := l.restoreFromRecycleBin(ctx, f.path, f.recycle)
+		if err != nil {
+			return err
+		}
+		if restored {
+			f.overwrite = true     // the restored path is expected to exist
+			f.ignoreContent = true // keep the restored content, only apply permission and attributes
+		}
+	}
+	if f.overwrite && f.backupEnabled() {
+		if err = l.backupFile(ctx, f, f.path); err != nil {
+			return
+		}
+	}
+	if err = l.writeFile(ctx, f); err != nil {
+		return
+	}
+	return l.runHook(ctx, f.hook, f.hook.onCreate)
+}
 
+func (l *linux) writeFile(ctx context.Context, f *file) (err error) {
 	if !f.overwrite {
 		if err = l.reservePath(ctx, f.path); err != nil {
 			return
@@ -64,14 +152,84 @@ func (l *linux) createFile(ctx context.Context, f *file) (err error) {
 		return
 	}
 
-	return l.setPermission(ctx, f.path, f.permission)
+	if err = l.setPermission(ctx, f.path, &f.permission); err != nil {
+		return
+	}
+	if err = l.setAttributes(ctx, f.path, f.attributes); err != nil {
+		return
+	}
+
+	f.appliedSalt = ""
+	if f.writeOnly() { // never store a plain checksum of a secret
+		salt := make([]byte, 16)
+		if _, err = rand.Read(salt); err != nil {
+			return
+		}
+		f.appliedSalt = hex.EncodeToString(salt)
+	}
+	if f.ignoreContent {
+		f.appliedSHA256, err = l.sha256sum(ctx, f.path, f.appliedSalt)
+	} else {
+		f.appliedSHA256 = f.sha256()
+	}
+	return
+}
+
+// ensureUnmodified fails with the diff of the remote content when it no longer matches the checksum applied by
+// terraform with salt, unless it already matches the content of next. A nil next means the file is about to be
+// deleted. Lines of the diff matching any of redact are hidden.
+func (l *linux) ensureUnmodified(ctx context.Context, path, applied, salt string, next *file, redact []string) (err error) {
+	if applied == "" {
+		return // nothing has been applied, e.g. after import
+	}
+	sum, err := l.sha256sum(ctx, path, salt)
+	if errors.Is(err, errPathNotExist) {
+		return nil
+	}
+	if err != nil || sum == applied || (next != nil && sum == next.sha256()) {
+		return
+	}
+
+	patterns, err := compileRedactPatterns(redact)
+	if err != nil {
+		return
+	}
+	current, err := l.cat(ctx, path)
+	if err != nil {
+		return
+	}
+	toFile, to := "/dev/null", ""
+	if next != nil {
+		toFile, to = next.path+" (planned)", next.content
+	}
+	return fmt.Errorf("'%s' has been modified outside of terraform since the last apply, "+
+		"set `force` to discard the modification:\n%s", path, redactDiff(unifiedDiff(path, toFile, current, to), patterns))
 }
 
 func (l *linux) deleteFile(ctx context.Context, f *file) (err error) {
 	if f == nil {
 		return
 	}
-	return l.remove(ctx, f.path, f.recyclePath)
+	if f.protected() && f.deleteStrategy != deleteStrategyRetain {
+		if err = l.ensureUnmodified(ctx, f.path, f.appliedSHA256, f.appliedSalt, nil, f.diffRedact()); err != nil {
+			return
+		}
+	}
+	if err = l.remove(ctx, f.path, newDeleteStrategy(f.deleteStrategy, f.recycle)); err != nil {
+		return
+	}
+	return l.runHook(ctx, f.hook, f.hook.onDestroy)
+}
+
+// backupFile backs up the existing paths, recording their locations in f. The previous locations are kept
+// when nothing is backed up.
+func (l *linux) backupFile(ctx context.Context, f *file, paths ...string) (err error) {
+	locations, err := l.backups(ctx, f.backupPath, paths...)
+	if err != nil || len(locations) == 0 {
+		return
+	}
+	f.backupLocation, f.backupLocations = locations[0], locations
+	return
 }
 
 func (l *linux) updateFile(ctx context.Context, old, new *file) (err error) {
@@ -81,7 +239,25 @@ func (l *linux) updateFile(ctx context.Context, old, new *file) (err error) {
 	if new == nil {
 		return l.deleteFile(ctx, old)
 	}
+	if old.path == new.path && old.permission == new.permission && old.attributes.equal(new.attributes) &&
+		(new.ignoreContent || old.content == new.content) {
+		return // nothing changed on remote
+	}
+	if new.protected() && !new.ignoreContent {
+		if err = l.ensureUnmodified(ctx, old.path, old.appliedSHA256, old.appliedSalt, new, new.diffRedact()); err != nil {
+			return
+		}
+	}
 
+	if new.backupEnabled() {
+		paths := []string{old.path}
+		if old.path != new.path && new.overwrite {
+			paths = append(paths, new.path) // about to be replaced
+		}
+		if err = l.backupFile(ctx, new, paths...); err != nil {
+			return
+		}
+	}
 	if old.path != new.path {
 		if !new.overwrite {
 			if err = l.reservePath(ctx, new.path); err != nil {
@@ -97,5 +273,10 @@ func (l *linux) updateFile(ctx context.Context, old, new *file) (err error) {
 	f := &file{}
 	*f = *new
 	f.overwrite = true
-	return l.createFile(ctx, f)
+	if err = l.writeFile(ctx, f); err != nil {
+		return
+	}
+	new.permission = f.permission
+	new.appliedSHA256, new.appliedSalt = f.appliedSHA256, f.appliedSalt
+	return l.runHook(ctx, f.hook, f.hook.onChange)
 }
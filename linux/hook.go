@@ -0,0 +1,40 @@
+package linux
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// errHook marks failures of hooks, which happen after the remote path has already been changed.
+var errHook = errors.New("while running hook")
+
+type hook struct {
+	onCreate  string
+	onChange  string
+	onDestroy string
+
+	workdir string
+	env     env
+}
+
+func (l *linux) runHook(ctx context.Context, h hook, body string) (err error) {
+	if body == "" {
+		return
+	}
+
+	sc := &script{
+		l: l,
+
+		workdir: h.workdir,
+		env:     h.env,
+		body:    body,
+	}
+	if sc.workdir == "" {
+		sc.workdir = "."
+	}
+	if _, err = sc.exec(ctx); err != nil {
+		return fmt.Errorf("%w: %w", errHook, err)
+	}
+	return
+}
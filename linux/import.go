@@ -0,0 +1,110 @@
+package linux
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/spf13/cast"
+)
+
+// parseImportID splits import ID in the form of `path` or `connection:path`.
+func parseImportID(id string) (conn, path string) {
+	if strings.HasPrefix(id, "/") {
+		return "", id
+	}
+	if i := strings.Index(id, ":"); i > 0 {
+		return id[:i], id[i+1:]
+	}
+	return "", id
+}
+
+// importDefaults populates the state with schema defaults, since import only knows the ID.
+func importDefaults(rd *schema.ResourceData, sm map[string]*schema.Schema) (err error) {
+	for k, s := range sm {
+		if s.Default == nil {
+			continue
+		}
+		if err = rd.Set(k, s.Default); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// importConnection parses the connection part of import ID. It is either the `id` of a `provider_override` known by
+// the provider, or the arguments of `provider_override` as comma separated `key=value` pairs starting with `id=`.
+func importConnection(lp *linuxPool, conn string) (info map[string]string, err error) {
+	if !strings.HasPrefix(conn, attrProviderID+"=") {
+		l, ok := lp.get(conn)
+		if !ok {
+			return nil, fmt.Errorf("connection %q is not known by the provider, "+
+				"specify it as `%s=%s,%s=...` instead", conn, attrProviderID, conn, attrProviderHost)
+		}
+		return l.connInfo, nil
+	}
+
+	info = map[string]string{}
+	for _, kv := range strings.Split(conn, ",") {
+		k, v, ok := strings.Cut(kv, "=")
+		if _, known := subSchemaProviderOverride[k]; !ok || !known {
+			return nil, fmt.Errorf("expected `key=value` with key of `provider_override`, got %q", kv)
+		}
+		if providerCredential(k) {
+			return nil, fmt.Errorf("%q must not be part of import ID", k)
+		}
+		info[k] = v
+	}
+	return
+}
+
+// importProviderOverride fills `provider_override` with the connection part of import ID, leaving credentials empty.
+// Unset arguments take their default value.
+func importProviderOverride(lp *linuxPool, rd *schema.ResourceData, attrProviderOverride, conn string) (err error) {
+	if conn == "" {
+		return
+	}
+	info, err := importConnection(lp, conn)
+	if err != nil {
+		return
+	}
+
+	m := make(map[string]interface{})
+	for k, s := range subSchemaProviderOverride {
+		v, ok := info[k]
+		if !ok && s.Default != nil {
+			v = cast.ToString(s.Default)
+		}
+		if providerCredential(k) {
+			v = ""
+		}
+		switch s.Type {
+		case schema.TypeInt:
+			if m[k], err = cast.ToIntE(v); err != nil && v != "" {
+				return fmt.Errorf("invalid %s %q: %w", k, v, err)
+			}
+		case schema.TypeBool:
+			if m[k], err = cast.ToBoolE(v); err != nil && v != "" {
+				return fmt.Errorf("invalid %s %q: %w", k, v, err)
+			}
+		default:
+			m[k] = v
+		}
+	}
+	return rd.Set(attrProviderOverride, []interface{}{m})
+}
+
+func importPath(lp *linuxPool, rd *schema.ResourceData, sm map[string]*schema.Schema, attrProviderOverride, attrPath string) (err error) {
+	conn, path := parseImportID(rd.Id())
+	if !strings.HasPrefix(path, "/") {
+		return fmt.Errorf("expected import ID in the form of `path` or `connection:path` with absolute path, got %q", rd.Id())
+	}
+
+	if err = importDefaults(rd, sm); err != nil {
+		return
+	}
+	if err = importProviderOverride(lp, rd, attrProviderOverride, conn); err != nil {
+		return
+	}
+	return rd.Set(attrPath, path)
+}
@@ -0,0 +1,39 @@
+package linux
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImportConnection(t *testing.T) {
+	lp := &linuxPool{pool: map[string]*linux{
+		"conn-1": {connInfo: map[string]string{attrProviderID: "conn-1", attrProviderHost: "10.0.0.1"}},
+	}}
+	tests := []struct {
+		name     string
+		conn     string
+		expected map[string]string
+		err      bool
+	}{
+		{name: "known id", conn: "conn-1", expected: map[string]string{attrProviderID: "conn-1", attrProviderHost: "10.0.0.1"}},
+		{name: "unknown id", conn: "conn-2", err: true},
+		{name: "arguments", conn: "id=conn-2,host=10.0.0.2,port=2222,host_key=ssh-ed25519 AAAA",
+			expected: map[string]string{attrProviderID: "conn-2", attrProviderHost: "10.0.0.2", attrProviderPort: "2222", attrProviderHostKey: "ssh-ed25519 AAAA"}},
+		{name: "unknown argument", conn: "id=conn-2,hostname=10.0.0.2", err: true},
+		{name: "argument without value", conn: "id=conn-2,host", err: true},
+		{name: "credential", conn: "id=conn-2,password=secret", err: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := importConnection(lp, tt.conn)
+			if tt.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, info)
+		})
+	}
+}
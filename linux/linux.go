@@ -6,7 +6,9 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math"
 	"net"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"sync"
@@ -107,6 +109,18 @@ func (l *linux) upload(ctx context.Context, path string, input io.Reader) (err e
 	return c.Upload(path, input)
 }
 
+// uploadDir uploads the content of local directory src into remote directory dst.
+func (l *linux) uploadDir(ctx context.Context, dst, src string) (err error) {
+	l.commMutex.Lock()
+	defer l.commMutex.Unlock()
+
+	c, err := l.communicator(ctx)
+	if err != nil {
+		return
+	}
+	return c.UploadDir(dst, strings.TrimSuffix(src, "/")+"/")
+}
+
 func (l *linux) uploadScript(ctx context.Context, path string, input io.Reader) (err error) {
 	l.commMutex.Lock()
 	defer l.commMutex.Unlock()
@@ -126,22 +140,102 @@ func (l *linux) scriptPath(ctx context.Context) string {
 	return c.ScriptPath()
 }
 
+// idUnmanaged marks owner or group that should be left untouched, similar to -1 in chown(2).
+const idUnmanaged = math.MaxUint32
+
+// permission with idUnmanaged owner or group, or empty mode, leaves the respective field untouched.
 type permission struct {
-	owner uint16
-	group uint16
-	mode  string
+	owner     uint32
+	group     uint32
+	mode      string
+	ownerName string
+	groupName string
+}
+
+// chownSpec returns the `owner:group` argument for chown, or empty string when both are unmanaged.
+func (p permission) chownSpec() string {
+	switch {
+	case p.owner != idUnmanaged && p.group != idUnmanaged:
+		return fmt.Sprintf("%d:%d", p.owner, p.group)
+	case p.owner != idUnmanaged:
+		return fmt.Sprintf("%d", p.owner)
+	case p.group != idUnmanaged:
+		return fmt.Sprintf(":%d", p.group)
+	}
+	return ""
+}
+
+func (l *linux) lookupID(ctx context.Context, database, name string) (id uint32, err error) {
+	stdout := new(bytes.Buffer)
+	cmd := fmt.Sprintf(`{ getent %s %s 2>/dev/null || %s ;} | head -n 1 | cut -d: -f3`,
+		database, shellescape.Quote(name),
+		shellescape.QuoteCommand([]string{"awk", "-F:", "-v", "n=" + name, "$1 == n", "/etc/" + database}),
+	)
+	if err = l.exec(ctx, &remote.Cmd{Command: cmd, Stdout: stdout}); err != nil {
+		return
+	}
+	out := strings.TrimSpace(stdout.String())
+	if out == "" {
+		err = fmt.Errorf("%s entry %q not found", database, name)
+		return
+	}
+	i, err := strconv.ParseUint(out, 10, 32)
+	if err != nil {
+		err = fmt.Errorf("while parsing %s id %q: %w", database, out, err)
+		return
+	}
+	return uint32(i), nil
 }
 
-func (l *linux) setPermission(ctx context.Context, path string, p permission) (err error) {
+func (l *linux) resolvePermission(ctx context.Context, p permission) (r permission, err error) {
+	r = p
+	if r.ownerName != "" {
+		if r.owner, err = l.lookupID(ctx, "passwd", r.ownerName); err != nil {
+			return
+		}
+	}
+	if r.groupName != "" {
+		if r.group, err = l.lookupID(ctx, "group", r.groupName); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// setPermission applies p to path. Symbolic mode in p is replaced with the resulting octal mode.
+func (l *linux) setPermission(ctx context.Context, path string, p *permission) (err error) {
+	r, err := l.resolvePermission(ctx, *p)
+	if err != nil {
+		return
+	}
 	pathSafe := shellescape.Quote(path)
-	cmd := fmt.Sprintf(`{ chown %d:%d %s && chmod %s %s ;}`,
-		p.owner, p.group, pathSafe, p.mode, pathSafe)
-	return l.exec(ctx, &remote.Cmd{Command: cmd})
+	var cmds []string
+	if spec := r.chownSpec(); spec != "" {
+		cmds = append(cmds, fmt.Sprintf(`chown %s %s`, spec, pathSafe))
+	}
+	if r.mode != "" {
+		cmds = append(cmds, fmt.Sprintf(`chmod %s %s`, shellescape.Quote(r.mode), pathSafe))
+	}
+	if len(cmds) == 0 {
+		return // nothing is managed
+	}
+	cmd := fmt.Sprintf(`{ %s ;}`, strings.Join(cmds, " && "))
+	if !modeSymbolicRegex.MatchString(r.mode) {
+		return l.exec(ctx, &remote.Cmd{Command: cmd})
+	}
+
+	stdout := new(bytes.Buffer)
+	cmd = fmt.Sprintf(`{ %s && stat -c %%a %s ;}`, cmd, pathSafe)
+	if err = l.exec(ctx, &remote.Cmd{Command: cmd, Stdout: stdout}); err != nil {
+		return
+	}
+	p.mode = normalizeMode(strings.TrimSpace(stdout.String()))
+	return
 }
 
 func (l *linux) getPermission(ctx context.Context, path string) (p permission, err error) {
 	stdout := new(bytes.Buffer)
-	cmd := shellescape.QuoteCommand([]string{"stat", "-c", "%u %g %a", path})
+	cmd := shellescape.QuoteCommand([]string{"stat", "-c", "%u %g %a %U %G", path})
 	err = l.exec(ctx, &remote.Cmd{Command: cmd, Stdout: stdout})
 	var exitError *remote.ExitError
 	if errors.As(err, &exitError) {
@@ -157,31 +251,53 @@ func (l *linux) getPermission(ctx context.Context, path string) (p permission, e
 		return
 	}
 	parts := strings.Split(strings.TrimSpace(out), " ")
-	if len(parts) != 3 {
+	if len(parts) != 5 {
 		err = fmt.Errorf("malformed output of %q: %q", cmd, out)
 		return
 	}
-	owner, err := strconv.ParseUint(parts[0], 10, 16)
+	owner, err := strconv.ParseUint(parts[0], 10, 32)
 	if err != nil {
 		err = fmt.Errorf("while parsing owner id %q: %w", parts[0], err)
 		return
 	}
-	group, err := strconv.ParseUint(parts[1], 10, 16)
+	group, err := strconv.ParseUint(parts[1], 10, 32)
 	if err != nil {
-		err = fmt.Errorf("while parsing group id %q: %w", parts[0], err)
+		err = fmt.Errorf("while parsing group id %q: %w", parts[1], err)
 		return
 	}
 	p = permission{
-		owner: uint16(owner),
-		group: uint16(group),
-		mode:  parts[2],
+		owner:     uint32(owner),
+		group:     uint32(group),
+		mode:      normalizeMode(parts[2]),
+		ownerName: parts[3],
+		groupName: parts[4],
+	}
+	if p.ownerName == "UNKNOWN" {
+		p.ownerName = ""
+	}
+	if p.groupName == "UNKNOWN" {
+		p.groupName = ""
 	}
 	return
 }
 
+func (l *linux) getMtime(ctx context.Context, path string) (t time.Time, err error) {
+	stdout := new(bytes.Buffer)
+	cmd := shellescape.QuoteCommand([]string{"stat", "-c", "%Y", path})
+	if err = l.exec(ctx, &remote.Cmd{Command: cmd, Stdout: stdout}); err != nil {
+		return
+	}
+	sec, err := strconv.ParseInt(strings.TrimSpace(stdout.String()), 10, 64)
+	if err != nil {
+		return t, fmt.Errorf("while parsing modification time %q: %w", stdout.String(), err)
+	}
+	return time.Unix(sec, 0), nil
+}
+
 func (l *linux) reservePath(ctx context.Context, path string) (err error) {
 	var exitError *remote.ExitError
-	cmd := fmt.Sprintf("[ ! -e %s ]", shellescape.Quote(path))
+	pathSafe := shellescape.Quote(path)
+	cmd := fmt.Sprintf("[ ! -e %s ] && [ ! -L %s ]", pathSafe, pathSafe)
 	if err = l.exec(ctx, &remote.Cmd{Command: cmd}); errors.As(err, &exitError) {
 		return fmt.Errorf("path '%s' exist", path)
 	}
@@ -202,33 +318,92 @@ func (l *linux) cat(ctx context.Context, path string) (s string, err error) {
 	return stdout.String(), nil
 }
 
+// sha256sum returns the hex encoded SHA256 checksum of the content of path prefixed with salt, or errPathNotExist
+// when it isn't a regular file.
+func (l *linux) sha256sum(ctx context.Context, path, salt string) (sum string, err error) {
+	stdout := new(bytes.Buffer)
+	pathSafe := shellescape.Quote(path)
+	cmd := fmt.Sprintf(`{ [ ! -f %s ] || sha256sum -- %s ;}`, pathSafe, pathSafe)
+	if salt != "" {
+		cmd = fmt.Sprintf(`{ [ ! -f %s ] || { printf '%%s' %s && cat -- %s ;} | sha256sum ;}`,
+			pathSafe, shellescape.Quote(salt), pathSafe)
+	}
+	if err = l.exec(ctx, &remote.Cmd{Command: cmd, Stdout: stdout}); err != nil {
+		return
+	}
+	fields := strings.Fields(stdout.String())
+	if len(fields) == 0 {
+		return "", errPathNotExist
+	}
+	return fields[0], nil
+}
+
 func (l *linux) mv(ctx context.Context, old, new string) (err error) {
 	cmd := shellescape.QuoteCommand([]string{"mv", old, new})
 	return l.exec(ctx, &remote.Cmd{Command: cmd})
 }
 
-func (l *linux) remove(ctx context.Context, path, recyclePath string) (err error) {
-	if path == "" {
+// replace atomically replaces path with input through a temporary sibling file,
+// keeping the ownership, mode and SELinux context of the existing path.
+func (l *linux) replace(ctx context.Context, path string, input io.Reader) (err error) {
+	tmp := filepath.Join(filepath.Dir(path), fmt.Sprintf(".%s.%d.tmp", filepath.Base(path), time.Now().UnixNano()))
+	if err = l.upload(ctx, tmp, input); err != nil {
 		return
 	}
 
-	var cmd string
-	if recyclePath != "" {
-		recycleFolder := fmt.Sprintf("%s/%d", recyclePath, time.Now().Unix())
-		cmd = fmt.Sprintf(`{ [ ! -e %s ] || { %s && %s ;} ;}`,
-			shellescape.Quote(path),
-			shellescape.QuoteCommand([]string{"mkdir", "-p", recycleFolder}),
-			shellescape.QuoteCommand([]string{"mv", path, recycleFolder}),
-		)
-	} else {
-		cmd = fmt.Sprintf(`{ [ ! -e %s ] || %s ;}`,
-			shellescape.Quote(path),
-			shellescape.QuoteCommand([]string{"rm", "-rf", path}),
-		)
-	}
+	tmpSafe, pathSafe := shellescape.Quote(tmp), shellescape.Quote(path)
+	cmd := fmt.Sprintf(`{ { [ ! -e %[2]s ] || { chown --reference=%[2]s %[1]s && chmod --reference=%[2]s %[1]s `+
+		`&& { chcon --reference=%[2]s %[1]s 2>/dev/null || true ;} ;} ;} && mv -f %[1]s %[2]s ;} || { rm -f %[1]s && false ;}`,
+		tmpSafe, pathSafe)
 	return l.exec(ctx, &remote.Cmd{Command: cmd})
 }
 
+func (l *linux) backup(ctx context.Context, path, backupPath string) (location string, err error) {
+	if backupPath == "" {
+		backupPath = filepath.Dir(path)
+	}
+	location = fmt.Sprintf("%s/%s.%d", backupPath, filepath.Base(path), time.Now().Unix())
+
+	stdout := new(bytes.Buffer)
+	cmd := fmt.Sprintf(`{ [ ! -e %s ] || { %s && L=%s && i=0 && while [ -e "$L" ]; do i=$((i+1)) && L=%s.$i; done && `+
+		`cp -a %s "$L" && printf %%s "$L" ;} ;}`, // suffixed when another backup was created within the same second
+		shellescape.Quote(path),
+		shellescape.QuoteCommand([]string{"mkdir", "-p", backupPath}),
+		shellescape.Quote(location),
+		shellescape.Quote(location),
+		shellescape.Quote(path),
+	)
+	if err = l.exec(ctx, &remote.Cmd{Command: cmd, Stdout: stdout}); err != nil {
+		return "", err
+	}
+	return stdout.String(), nil
+}
+
+// backups backs up each existing path into backupPath and returns the locations of the created backups.
+func (l *linux) backups(ctx context.Context, backupPath string, paths ...string) (locations []string, err error) {
+	for _, path := range paths {
+		location, err := l.backup(ctx, path, backupPath)
+		if err != nil {
+			return nil, err
+		}
+		if location != "" {
+			locations = append(locations, location)
+		}
+	}
+	return
+}
+
+// remove deletes path using the given strategy, defaulting to deleteRemove.
+func (l *linux) remove(ctx context.Context, path string, s deleteStrategy) (err error) {
+	if path == "" {
+		return
+	}
+	if s == nil {
+		s = deleteRemove{}
+	}
+	return s.delete(ctx, l, path)
+}
+
 func (l *linux) lforwardTCP(ctx context.Context, local string, remote string) (err error) {
 	comm, err := l.communicator(ctx)
 	if err != nil {
@@ -26,3 +26,11 @@ func (lp *linuxPool) getOrSet(id string, l *linux) (*linux, error) {
 	}
 	return lg, nil
 }
+
+func (lp *linuxPool) get(id string) (l *linux, ok bool) {
+	lp.mut.Lock()
+	defer lp.mut.Unlock()
+
+	l, ok = lp.pool[id]
+	return
+}
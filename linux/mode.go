@@ -0,0 +1,146 @@
+package linux
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+var (
+	modeOctalRegex    = regexp.MustCompile(`^[0-7]{3,4}$`)
+	modeSymbolicRegex = regexp.MustCompile(`^[ugoa]*([-+=]([rwxXst]*|[ugo]))+(,[ugoa]*([-+=]([rwxXst]*|[ugo]))+)*$`)
+)
+
+func validateMode(i interface{}, k string) (warnings []string, errs []error) {
+	v, ok := i.(string)
+	if !ok {
+		errs = append(errs, fmt.Errorf("expected type of %s to be string", k))
+		return
+	}
+	if !modeOctalRegex.MatchString(v) && !modeSymbolicRegex.MatchString(v) {
+		errs = append(errs, fmt.Errorf("invalid linux permission for %s: %q", k, v))
+	}
+	return
+}
+
+// normalizeMode strips redundant leading zeros from octal mode so that `0644` and `644` are considered equal.
+// Symbolic mode is returned as is.
+func normalizeMode(mode string) string {
+	if !modeOctalRegex.MatchString(mode) {
+		return mode
+	}
+	m, err := strconv.ParseUint(mode, 8, 32)
+	if err != nil {
+		return mode
+	}
+	return fmt.Sprintf("%03o", m)
+}
+
+// applySymbolicMode computes the result of `chmod <symbolic>` against the given octal mode.
+// Omitted `who` is treated as `a`, i.e. umask is not taken into account.
+func applySymbolicMode(mode uint32, symbolic string, isDir bool) uint32 {
+	for _, clause := range strings.Split(symbolic, ",") {
+		i := strings.IndexAny(clause, "-+=")
+		if i < 0 {
+			continue
+		}
+
+		var who uint32
+		for _, c := range clause[:i] {
+			switch c {
+			case 'u':
+				who |= 04700
+			case 'g':
+				who |= 02070
+			case 'o':
+				who |= 01007
+			case 'a':
+				who |= 07777
+			}
+		}
+		if who == 0 {
+			who = 07777
+		}
+
+		actions := clause[i:]
+		for len(actions) > 0 {
+			op := actions[0]
+			j := strings.IndexAny(actions[1:], "-+=")
+			if j < 0 {
+				j = len(actions) - 1
+			}
+			perms := actions[1 : j+1]
+			actions = actions[j+1:]
+
+			var bits uint32
+			for _, c := range perms {
+				switch c {
+				case 'r':
+					bits |= 0444
+				case 'w':
+					bits |= 0222
+				case 'x':
+					bits |= 0111
+				case 'X':
+					if isDir || mode&0111 != 0 {
+						bits |= 0111
+					}
+				case 's':
+					bits |= 06000
+				case 't':
+					bits |= 01000
+				case 'u':
+					bits |= ((mode >> 6) & 7) * 0111
+				case 'g':
+					bits |= ((mode >> 3) & 7) * 0111
+				case 'o':
+					bits |= (mode & 7) * 0111
+				}
+			}
+			bits &= who
+
+			switch op {
+			case '+':
+				mode |= bits
+			case '-':
+				mode &^= bits
+			case '=':
+				clear := who
+				if isDir {
+					clear &^= 06000 // chmod preserves set-user-ID and set-group-ID of directories
+				}
+				mode = mode&^clear | bits
+			}
+		}
+	}
+	return mode
+}
+
+func diffSuppressMode(isDir bool) schema.SchemaDiffSuppressFunc {
+	return func(k, old, new string, d *schema.ResourceData) bool {
+		if old == "" || new == "" {
+			return false
+		}
+		if !modeSymbolicRegex.MatchString(new) {
+			if isDir && len(new) == 3 {
+				// chmod preserves set-user-ID and set-group-ID of directories
+				if m, err := strconv.ParseUint(old, 8, 32); err == nil {
+					old = fmt.Sprintf("%03o", m&^06000)
+				}
+			}
+			return normalizeMode(old) == normalizeMode(new)
+		}
+
+		if !modeOctalRegex.MatchString(old) {
+			return old == new
+		}
+		m, err := strconv.ParseUint(old, 8, 32)
+		if err != nil {
+			return false
+		}
+		return applySymbolicMode(uint32(m), new, isDir) == uint32(m)
+	}
+}
@@ -2,8 +2,11 @@ package linux
 
 import (
 	"context"
+	"fmt"
+	"math"
 	"sync"
 
+	"github.com/hashicorp/go-cty/cty"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/spf13/cast"
@@ -187,7 +190,8 @@ func newLinuxFromSchema(d *schema.ResourceData) (l *linux, err error) {
 	return &linux{connInfo: connInfo, commOnce: sync.Once{}}, nil
 }
 
-func getLinux(lp *linuxPool, d *schema.ResourceData) (l *linux, err error) {
+// getLinux returns the linux of the resource, which is either a *schema.ResourceData or a *schema.ResourceDiff.
+func getLinux(lp *linuxPool, d interface{ Get(string) interface{} }) (l *linux, err error) {
 	var con map[string]string
 
 	pro := cast.ToSlice(d.Get(attrScriptProviderOverride))
@@ -196,9 +200,72 @@ func getLinux(lp *linuxPool, d *schema.ResourceData) (l *linux, err error) {
 	}
 
 	con = cast.ToStringMapString(pro[0])
+	if pl, ok := lp.get(con[attrProviderID]); ok {
+		for k := range con {
+			if providerCredential(k) && con[k] == "" {
+				con[k] = pl.connInfo[k] // e.g. imported, which never stores credentials
+			}
+		}
+	}
 	return lp.getOrSet(con[attrProviderID], &linux{connInfo: con, commOnce: sync.Once{}})
 }
 
+// providerCredential reports whether attr of `provider_override` is a credential, e.g. `password`. Credentials are
+// never part of import ID nor imported state.
+func providerCredential(attr string) bool {
+	s, ok := subSchemaProviderOverride[attr]
+	return ok && s.Sensitive
+}
+
+// configured reports whether attr is explicitly set, even to an unknown value, in the raw configuration.
+func configured(config cty.Value, attr string) bool {
+	if config.IsNull() || !config.IsKnown() || !config.Type().IsObjectType() || !config.Type().HasAttribute(attr) {
+		return false
+	}
+	return !config.GetAttr(attr).IsNull()
+}
+
+// unmanagedPermission marks owner, group and mode that are not set in the configuration as unmanaged.
+// Empty attrMode means the resource has no mode.
+func unmanagedPermission(config cty.Value, p permission, attrOwner, attrOwnerName, attrGroup, attrGroupName, attrMode string) permission {
+	if !configured(config, attrOwner) && !configured(config, attrOwnerName) {
+		p.owner, p.ownerName = idUnmanaged, ""
+	}
+	if !configured(config, attrGroup) && !configured(config, attrGroupName) {
+		p.group, p.groupName = idUnmanaged, ""
+	}
+	if attrMode != "" && !configured(config, attrMode) {
+		p.mode = ""
+	}
+	return p
+}
+
+// unmanagedAttributes marks selinux context, acl and xattrs that are not set in the configuration as unmanaged.
+func unmanagedAttributes(config cty.Value, a attributes, attrSelinuxContext, attrACL, attrXattrs string) attributes {
+	if !configured(config, attrSelinuxContext) {
+		a.selinuxContext = ""
+	}
+	if !configured(config, attrACL) {
+		a.acl = nil
+	}
+	if !configured(config, attrXattrs) {
+		a.xattrs = nil
+	}
+	return a
+}
+
+func validateID(i interface{}, k string) (warnings []string, errs []error) {
+	v, ok := i.(int)
+	if !ok {
+		errs = append(errs, fmt.Errorf("expected type of %s to be integer", k))
+		return
+	}
+	if v < 0 || uint64(v) >= math.MaxUint32 {
+		errs = append(errs, fmt.Errorf("expected %s to be a valid linux id, got %d", k, v))
+	}
+	return
+}
+
 func Provider() *schema.Provider {
 	return &schema.Provider{
 		Schema: schemaProvider,
@@ -213,11 +280,18 @@ func Provider() *schema.Provider {
 		DataSourcesMap: map[string]*schema.Resource{
 			"linux_script":        scriptDataSource(),
 			"linux_local_forward": localforwardDataSource(),
+			"linux_file":          fileDataSource(),
+			"linux_directory":     directoryDataSource(),
+			"linux_recycle_bin":   recycleBinDataSource(),
 		},
 		ResourcesMap: map[string]*schema.Resource{
-			"linux_file":      fileResource(),
-			"linux_directory": directoryResource(),
-			"linux_script":    scriptResource(),
+			"linux_file":               fileResource(),
+			"linux_directory":          directoryResource(),
+			"linux_directory_contents": directoryContentsResource(),
+			"linux_symlink":            symlinkResource(),
+			"linux_file_line":          fileLineResource(),
+			"linux_config_value":       configValueResource(),
+			"linux_script":             scriptResource(),
 		},
 	}
 }
@@ -0,0 +1,126 @@
+package linux
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/spf13/cast"
+)
+
+const (
+	attrRecycleBinProviderOverride       = "provider_override"
+	attrRecycleBinPath                   = "path"
+	attrRecycleBinGenerations            = "generations"
+	attrRecycleBinGenerationName         = "name"
+	attrRecycleBinGenerationTime         = "time"
+	attrRecycleBinGenerationItems        = "items"
+	attrRecycleBinGenerationItemName     = "name"
+	attrRecycleBinGenerationItemOriginal = "original_path"
+)
+
+var schemaRecycleBinDataSource = map[string]*schema.Schema{
+	attrRecycleBinProviderOverride: {
+		Type:     schema.TypeList,
+		Optional: true,
+		MaxItems: 1,
+		Elem: &schema.Resource{
+			Schema: subSchemaProviderOverride,
+		},
+	},
+
+	attrRecycleBinPath: {
+		Type:        schema.TypeString,
+		Required:    true,
+		Description: "The `recycle_path` used by other resources",
+	},
+	attrRecycleBinGenerations: {
+		Type:     schema.TypeList,
+		Computed: true,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				attrRecycleBinGenerationName: {
+					Type:        schema.TypeString,
+					Computed:    true,
+					Description: "Name of the generation directory, which is the unix timestamp of when items were recycled",
+				},
+				attrRecycleBinGenerationTime: {
+					Type:        schema.TypeString,
+					Computed:    true,
+					Description: "Time of when items were recycled in RFC3339 format",
+				},
+				attrRecycleBinGenerationItems: {
+					Type:     schema.TypeList,
+					Computed: true,
+					Elem: &schema.Resource{
+						Schema: map[string]*schema.Schema{
+							attrRecycleBinGenerationItemName: {
+								Type:     schema.TypeString,
+								Computed: true,
+							},
+							attrRecycleBinGenerationItemOriginal: {
+								Type:        schema.TypeString,
+								Computed:    true,
+								Description: "Path of the item before it was recycled. Empty if unknown",
+							},
+						},
+					},
+				},
+			},
+		},
+	},
+}
+
+type handlerRecycleBinDataSource struct{}
+
+func (handlerRecycleBinDataSource) updateResourceData(gens []recycleGeneration, rd *schema.ResourceData) (err error) {
+	list := make([]interface{}, 0, len(gens))
+	for _, g := range gens {
+		items := make([]interface{}, 0, len(g.items))
+		for _, i := range g.items {
+			items = append(items, map[string]interface{}{
+				attrRecycleBinGenerationItemName:     i.name,
+				attrRecycleBinGenerationItemOriginal: i.originalPath,
+			})
+		}
+		list = append(list, map[string]interface{}{
+			attrRecycleBinGenerationName:  g.name,
+			attrRecycleBinGenerationTime:  g.time.UTC().Format(time.RFC3339),
+			attrRecycleBinGenerationItems: items,
+		})
+	}
+	return rd.Set(attrRecycleBinGenerations, list)
+}
+
+func (h handlerRecycleBinDataSource) Read(ctx context.Context, rd *schema.ResourceData, meta interface{}) (d diag.Diagnostics) {
+	l, err := getLinux(meta.(*linuxPool), rd)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	rd.SetId("static")
+	path := cast.ToString(rd.Get(attrRecycleBinPath))
+	var gens []recycleGeneration
+	_, err = l.getPermission(ctx, path)
+	if err == nil {
+		gens, err = l.listRecycleBin(ctx, path)
+	}
+	if err != nil && !errors.Is(err, errPathNotExist) {
+		return diag.FromErr(err)
+	}
+
+	if err = h.updateResourceData(gens, rd); err != nil {
+		return diag.FromErr(err)
+	}
+	return
+}
+
+func recycleBinDataSource() *schema.Resource {
+	h := handlerRecycleBinDataSource{}
+	return &schema.Resource{
+		Schema:      schemaRecycleBinDataSource,
+		ReadContext: h.Read,
+	}
+}
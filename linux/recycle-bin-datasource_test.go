@@ -0,0 +1,130 @@
+package linux
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccLinuxDataRecycleBinRestore(t *testing.T) {
+	conf1 := tfConf{
+		Provider: testAccProvider,
+		Extra: tfmap{
+			"path":         fmt.Sprintf(`"/tmp/linux/%s"`, acctest.RandString(16)),
+			"recycle_path": fmt.Sprintf(`"/tmp/recycle/%s"`, acctest.RandString(16)),
+			"file":         "true",
+		},
+	}
+	conf2 := conf1.Copy(func(tc *tfConf) {
+		tc.Extra["file"] = "false"
+	})
+	conf3 := conf1.Copy(func(tc *tfConf) {
+		tc.Extra["restore"] = "true"
+		tc.Extra["content"] = `"different"`
+	})
+
+	resource.Test(t, resource.TestCase{
+		ExternalProviders: map[string]resource.ExternalProvider{"null": {}},
+		PreCheck:          testAccPreCheckConnection(t),
+		Providers:         testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccLinuxDataRecycleBinRestoreConfig(t, conf1),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.linux_recycle_bin.bin", "generations.#", "0"),
+				),
+			},
+			{
+				Config: testAccLinuxDataRecycleBinRestoreConfig(t, conf2),
+			},
+			{
+				Config: testAccLinuxDataRecycleBinRestoreConfig(t, conf3),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.linux_recycle_bin.bin", "generations.#", "1"),
+					resource.TestCheckResourceAttr("data.linux_recycle_bin.bin", "generations.0.items.#", "1"),
+					resource.TestCheckResourceAttr("data.linux_recycle_bin.bin", "generations.0.items.0.name", "file.txt"),
+					resource.TestCheckResourceAttrPair("data.linux_recycle_bin.bin", "generations.0.items.0.original_path",
+						"linux_file.file", "path"),
+				),
+			},
+			{
+				Config:   testAccLinuxDataRecycleBinRestoreConfig(t, conf3),
+				PlanOnly: true, // the restored content is kept
+			},
+		},
+	})
+}
+
+func testAccLinuxDataRecycleBinRestoreConfig(t *testing.T, conf tfConf) (s string) {
+	tf := heredoc.Doc(`
+		provider "linux" {
+			alias = "test"
+		    {{- .Provider.Serialize | nindent 4 }}
+		}
+
+		resource "null_resource" "destroy_checker" {
+		    triggers = {
+		        path = {{ .Extra.path }}
+		        recycle_path = {{ .Extra.recycle_path }}
+		    }
+		    connection {
+		        type = "ssh"
+		        {{- .Provider.Serialize | nindent 8 }}
+		    }
+		    provisioner "remote-exec" {
+		        when = destroy
+		        inline = [ "rm -rf '${self.triggers["path"]}' '${self.triggers["recycle_path"]}'" ]
+		    }
+		}
+
+		data "linux_recycle_bin" "bin" {
+			provider = "linux.test"
+		    depends_on = [ null_resource.destroy_checker ]
+
+		    path = {{ .Extra.recycle_path }}
+		}
+
+		{{- if eq .Extra.file "true" }}
+
+		resource "linux_file" "file" {
+			provider = "linux.test"
+		    depends_on = [ data.linux_recycle_bin.bin ]
+
+		    path = "${ {{- .Extra.path -}} }/file.txt"
+		    content = {{ .Extra.content | default "\"helloworld\"" }}
+		    recycle_path = {{ .Extra.recycle_path }}
+		    recycle_max_items = 1
+		    restore_from_recycle = {{ .Extra.restore | default "false" }}
+		    ignore_content = {{ .Extra.restore | default "false" }}
+		}
+
+		resource "null_resource" "restore_validator" {
+		    count = {{ .Extra.restore | default "false" }} ? 1 : 0
+		    triggers = {
+		        path = linux_file.file.path
+		        recycle_path = {{ .Extra.recycle_path }}
+		    }
+		    connection {
+		        type = "ssh"
+		        {{- .Provider.Serialize | nindent 8 }}
+		    }
+		    provisioner "remote-exec" {
+		        inline = [
+		            <<-EOF
+		                [ "$(cat '${self.triggers["path"]}')" = "helloworld" ] || exit 101
+		                [ -z "$(find '${self.triggers["recycle_path"]}' -name file.txt)" ] || exit 102
+		            EOF
+		        ]
+		    }
+		}
+		{{- end }}
+	`)
+	s, err := conf.compile(tf)
+	t.Log(s)
+	require.NoError(t, err, "compile template failed")
+	return
+}
@@ -0,0 +1,176 @@
+package linux
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"al.essio.dev/pkg/shellescape"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform/communicator/remote"
+	"github.com/spf13/cast"
+)
+
+// recycleIndex is the name of the file inside each generation that records the original paths of its items.
+const recycleIndex = ".recycle.index"
+
+// recycleBin is a directory of generations named after the unix timestamp when items were moved into them.
+// Empty retention or zero maxItems keeps generations forever.
+type recycleBin struct {
+	path      string
+	retention string
+	maxItems  int
+}
+
+func newRecycleBin(rd *schema.ResourceData, attrPath, attrRetention, attrMaxItems string) recycleBin {
+	return recycleBin{
+		path:      cast.ToString(rd.Get(attrPath)),
+		retention: cast.ToString(rd.Get(attrRetention)),
+		maxItems:  cast.ToInt(rd.Get(attrMaxItems)),
+	}
+}
+
+func validateRetention(i interface{}, k string) (warnings []string, errs []error) {
+	v, ok := i.(string)
+	if !ok {
+		errs = append(errs, fmt.Errorf("expected type of %s to be string", k))
+		return
+	}
+	if v == "" {
+		return
+	}
+	if d, err := time.ParseDuration(v); err != nil || d < 0 {
+		errs = append(errs, fmt.Errorf("invalid duration for %s: %q", k, v))
+	}
+	return
+}
+
+type recycleItem struct {
+	name         string
+	originalPath string
+}
+
+type recycleGeneration struct {
+	name  string
+	time  time.Time
+	items []recycleItem
+}
+
+// listRecycleBin returns generations sorted from the oldest, using two remote executions.
+func (l *linux) listRecycleBin(ctx context.Context, path string) (gens []recycleGeneration, err error) {
+	entries, err := l.listDirectory(ctx, path, "", 2)
+	if err != nil {
+		return
+	}
+
+	stdout := new(bytes.Buffer)
+	cmd := fmt.Sprintf(`{ %s 2>/dev/null || true ;}`, shellescape.QuoteCommand([]string{
+		"find", path, "-mindepth", "2", "-maxdepth", "2", "-name", recycleIndex, "-exec", "grep", "-H", "", "{}", "+",
+	}))
+	if err = l.exec(ctx, &remote.Cmd{Command: cmd, Stdout: stdout}); err != nil {
+		return
+	}
+	origins := map[string]string{} // keyed by generation/name
+	for _, line := range strings.Split(stdout.String(), "\n") {
+		index, original, ok := strings.Cut(line, "/"+recycleIndex+":")
+		if !ok {
+			continue
+		}
+		gen := filepath.Base(index)
+		origins[gen+"/"+filepath.Base(original)] = original
+	}
+
+	byName := map[string]*recycleGeneration{}
+	for _, e := range entries {
+		gen, item, nested := strings.Cut(e.name, "/")
+		ts, perr := strconv.ParseInt(gen, 10, 64)
+		if perr != nil {
+			continue // not a generation
+		}
+		g, ok := byName[gen]
+		if !ok {
+			g = &recycleGeneration{name: gen, time: time.Unix(ts, 0)}
+			byName[gen] = g
+		}
+		if nested && item != recycleIndex {
+			g.items = append(g.items, recycleItem{name: item, originalPath: origins[e.name]})
+		}
+	}
+	for _, g := range byName {
+		gens = append(gens, *g)
+	}
+	sort.Slice(gens, func(i, j int) bool { return gens[i].time.Before(gens[j].time) })
+	return
+}
+
+// pruneRecycleBin removes generations older than the retention and the oldest generations exceeding maxItems.
+func (l *linux) pruneRecycleBin(ctx context.Context, rb recycleBin) (err error) {
+	retention, _ := time.ParseDuration(rb.retention)
+	if rb.path == "" || (retention <= 0 && rb.maxItems <= 0) {
+		return
+	}
+	gens, err := l.listRecycleBin(ctx, rb.path)
+	if err != nil {
+		return
+	}
+
+	var stale []string
+	for i, g := range gens {
+		expired := retention > 0 && time.Since(g.time) > retention
+		exceeded := rb.maxItems > 0 && len(gens)-i > rb.maxItems
+		if expired || exceeded {
+			stale = append(stale, filepath.Join(rb.path, g.name))
+		}
+	}
+	if len(stale) == 0 {
+		return
+	}
+	cmd := shellescape.QuoteCommand(append([]string{"rm", "-rf", "--"}, stale...))
+	if err = l.exec(ctx, &remote.Cmd{Command: cmd}); err != nil {
+		return fmt.Errorf("while pruning recycle bin: %w", err)
+	}
+	return
+}
+
+// restoreFromRecycleBin moves the latest recycled item whose original path is path back into place.
+// It returns false when there is no such item or when path already exists.
+func (l *linux) restoreFromRecycleBin(ctx context.Context, path string, rb recycleBin) (restored bool, err error) {
+	if rb.path == "" {
+		return
+	}
+	if _, err = l.getPermission(ctx, path); !errors.Is(err, errPathNotExist) {
+		return
+	}
+	if _, err = l.getPermission(ctx, rb.path); err != nil {
+		if errors.Is(err, errPathNotExist) {
+			err = nil
+		}
+		return
+	}
+	gens, err := l.listRecycleBin(ctx, rb.path)
+	if err != nil {
+		return
+	}
+	for i := len(gens) - 1; i >= 0; i-- {
+		for _, item := range gens[i].items {
+			if item.originalPath != path {
+				continue
+			}
+			if err = l.mkdirp(ctx, filepath.Dir(path)); err != nil {
+				return
+			}
+			src := filepath.Join(rb.path, gens[i].name, item.name)
+			if err = l.exec(ctx, &remote.Cmd{Command: shellescape.QuoteCommand([]string{"mv", "-T", src, path})}); err != nil {
+				return false, fmt.Errorf("while restoring '%s' from recycle bin: %w", src, err)
+			}
+			return true, nil
+		}
+	}
+	return
+}
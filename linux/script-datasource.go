@@ -20,10 +20,22 @@ var schemaScriptDataSource = func() (m map[string]*schema.Schema) {
 			m[k].Elem = &schema.Resource{
 				Schema: map[string]*schema.Schema{
 					attrScriptLifecycleCommandRead: v.Elem.(*schema.Resource).Schema[attrScriptLifecycleCommandRead],
+					attrScriptLifecycleSettings: {
+						Type:     schema.TypeList,
+						Optional: true,
+						MaxItems: 1,
+						Elem: &schema.Resource{
+							Schema: map[string]*schema.Schema{
+								attrScriptLifecycleCommandRead: subSchemaScriptLifecycleCommandSettings,
+							},
+						},
+					},
 				},
 			}
 
 		case attrScriptTriggers:
+		case attrScriptSensitiveEnvironmentWO: // write-only is not supported by data sources
+		case attrScriptSensitiveEnvironmentWOVersion:
 		case attrScriptDirtyOutput:
 		case attrScriptFaultyOutput:
 		}
@@ -41,9 +53,12 @@ func (h handlerScriptDataSource) Read(ctx context.Context, rd *schema.ResourceDa
 		return diag.FromErr(err)
 	}
 
-	err = h.hsr.read(ctx, rd, l)
+	res, err := h.hsr.read(ctx, rd, l)
 	if err != nil {
 		d = diag.FromErr(err)
+	} else {
+		h.hsr.setResult(rd, res)
+		d = h.hsr.warnings(rd, attrScriptLifecycleCommandRead, res)
 	}
 	rd.SetId("static")
 	return
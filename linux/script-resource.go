@@ -2,20 +2,33 @@ package linux
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"reflect"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/hashicorp/go-cty/cty"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	"github.com/hashicorp/terraform/communicator/remote"
 	"github.com/spf13/cast"
 )
 
+type getchange interface {
+	GetChange(key string) (interface{}, interface{})
+}
+
 type haschange interface {
 	HasChange(key string) bool
+	getchange
+}
+
+type getrawconfigat interface {
+	GetRawConfigAt(valPath cty.Path) (cty.Value, diag.Diagnostics)
 }
 
 const (
@@ -26,19 +39,68 @@ const (
 	attrScriptLifecycleCommandRead   = "read"
 	attrScriptLifecycleCommandUpdate = "update"
 	attrScriptLifecycleCommandDelete = "delete"
+	attrScriptLifecycleSettings      = "settings"
+	attrScriptTimeout                = "timeout"
 	attrScriptInterpreter            = "interpreter"
-
-	attrScriptTriggers             = "triggers"
-	attrScriptEnvironment          = "environment"
-	attrScriptSensitiveEnvironment = "sensitive_environment"
-	attrScriptWorkingDirectory     = "working_directory"
-
-	attrScriptOutput = "output"
+	attrScriptExecutionMode          = "execution_mode"
+	attrScriptOutputFormat           = "output_format"
+	attrScriptWarnOnStderr           = "warn_on_stderr"
+
+	attrScriptTriggers                      = "triggers"
+	attrScriptEnvironment                   = "environment"
+	attrScriptSensitiveEnvironment          = "sensitive_environment"
+	attrScriptSensitiveEnvironmentWO        = "sensitive_environment_wo"
+	attrScriptSensitiveEnvironmentWOVersion = "sensitive_environment_wo_version"
+	attrScriptWorkingDirectory              = "working_directory"
+
+	attrScriptOutput       = "output"
+	attrScriptOutputValues = "output_values"
+	attrScriptStderr       = "stderr"
+	attrScriptExitCode     = "exit_code"
 
 	attrScriptDirtyOutput  = "__dirty_output__"
 	attrScriptFaultyOutput = "__faulty_output__"
 )
 
+// scriptLifecycles are the lifecycle commands, which are also the blocks of settings.
+var scriptLifecycles = []string{attrScriptLifecycleCommandCreate, attrScriptLifecycleCommandRead,
+	attrScriptLifecycleCommandUpdate, attrScriptLifecycleCommandDelete}
+
+// attrScriptLifecycleInputSettings is the key reported when the settings that are inputs of the commands change.
+const attrScriptLifecycleInputSettings = attrScriptLifecycleCommands + ".0." + attrScriptLifecycleSettings
+
+// subSchemaScriptLifecycleCommandSettings overrides the arguments used to run a single lifecycle command.
+var subSchemaScriptLifecycleCommandSettings = &schema.Schema{
+	Type:     schema.TypeList,
+	Optional: true,
+	MaxItems: 1,
+	Elem: &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			attrScriptInterpreter: {
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			attrScriptEnvironment: {
+				Type:     schema.TypeMap,
+				Optional: true,
+				Elem:     schema.TypeString,
+			},
+			attrScriptWorkingDirectory: {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			attrScriptTimeout: {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validateScriptTimeout,
+			},
+		},
+	},
+}
+
 var schemaScriptResource = map[string]*schema.Schema{
 	attrScriptProviderOverride: {
 		Type:     schema.TypeList,
@@ -71,6 +133,19 @@ var schemaScriptResource = map[string]*schema.Schema{
 					Type:     schema.TypeString,
 					Required: true,
 				},
+				attrScriptLifecycleSettings: {
+					Type:     schema.TypeList,
+					Optional: true,
+					MaxItems: 1,
+					Elem: &schema.Resource{
+						Schema: map[string]*schema.Schema{
+							attrScriptLifecycleCommandCreate: subSchemaScriptLifecycleCommandSettings,
+							attrScriptLifecycleCommandUpdate: subSchemaScriptLifecycleCommandSettings,
+							attrScriptLifecycleCommandRead:   subSchemaScriptLifecycleCommandSettings,
+							attrScriptLifecycleCommandDelete: subSchemaScriptLifecycleCommandSettings,
+						},
+					},
+				},
 			},
 		},
 	},
@@ -82,6 +157,27 @@ var schemaScriptResource = map[string]*schema.Schema{
 		},
 	},
 
+	attrScriptExecutionMode: {
+		Type:         schema.TypeString,
+		Optional:     true,
+		Default:      scriptExecutionModeUpload,
+		ValidateFunc: validation.StringInSlice(scriptExecutionModes, false),
+		Description:  "Either `upload` to upload each command as a script file before executing it, or `stdin` to stream it into the stdin of the interpreter",
+	},
+	attrScriptOutputFormat: {
+		Type:         schema.TypeString,
+		Optional:     true,
+		Default:      scriptOutputFormatRaw,
+		ValidateFunc: validation.StringInSlice(scriptOutputFormats, false),
+		Description:  "Either `raw`, `json` or `kv`. Output of `read` commands in `json` or `kv` format is validated and parsed into `output_values`",
+	},
+	attrScriptWarnOnStderr: {
+		Type:        schema.TypeBool,
+		Optional:    true,
+		Default:     false,
+		Description: "Surface stderr of successful commands as warnings",
+	},
+
 	attrScriptTriggers: {
 		Type:     schema.TypeMap,
 		Optional: true,
@@ -98,6 +194,22 @@ var schemaScriptResource = map[string]*schema.Schema{
 		Elem:      schema.TypeString,
 		Sensitive: true,
 	},
+	attrScriptSensitiveEnvironmentWO: {
+		Type:         schema.TypeString,
+		Optional:     true,
+		WriteOnly:    true,
+		Sensitive:    true,
+		ValidateFunc: validateEnvironmentJSON,
+		RequiredWith: []string{attrScriptSensitiveEnvironmentWOVersion},
+		Description:  "JSON encoded map of write-only environment, e.g. `jsonencode({...})`, which is never stored in state. Only available to `create` and `update` commands",
+	},
+	attrScriptSensitiveEnvironmentWOVersion: {
+		Type:         schema.TypeInt,
+		Optional:     true,
+		ValidateFunc: validation.IntAtLeast(1),
+		RequiredWith: []string{attrScriptSensitiveEnvironmentWO},
+		Description:  "Version of `sensitive_environment_wo`. Changes to `sensitive_environment_wo` are only applied when this changes",
+	},
 	attrScriptWorkingDirectory: {
 		Type:     schema.TypeString,
 		Optional: true,
@@ -108,6 +220,19 @@ var schemaScriptResource = map[string]*schema.Schema{
 		Type:     schema.TypeString,
 		Computed: true,
 	},
+	attrScriptOutputValues: {
+		Type:     schema.TypeMap,
+		Computed: true,
+		Elem:     &schema.Schema{Type: schema.TypeString},
+	},
+	attrScriptStderr: {
+		Type:     schema.TypeString,
+		Computed: true,
+	},
+	attrScriptExitCode: {
+		Type:     schema.TypeInt,
+		Computed: true,
+	},
 
 	attrScriptDirtyOutput: {
 		Type:     schema.TypeString,
@@ -134,18 +259,25 @@ func (h handlerScriptResource) attrCommands() map[string]bool {
 	return map[string]bool{
 		attrScriptLifecycleCommands: true,
 		attrScriptInterpreter:       true,
+		attrScriptExecutionMode:     true,
+		attrScriptOutputFormat:      true,
+		attrScriptWarnOnStderr:      true,
 	}
 }
 func (h handlerScriptResource) attrInputs() map[string]bool {
 	return map[string]bool{
-		attrScriptEnvironment:          true,
-		attrScriptSensitiveEnvironment: true,
-		attrScriptWorkingDirectory:     true,
+		attrScriptEnvironment:                   true,
+		attrScriptSensitiveEnvironment:          true,
+		attrScriptSensitiveEnvironmentWOVersion: true,
+		attrScriptWorkingDirectory:              true,
 	}
 }
 func (h handlerScriptResource) attrOutputs() map[string]bool {
 	return map[string]bool{
-		attrScriptOutput: true,
+		attrScriptOutput:       true,
+		attrScriptOutputValues: true,
+		attrScriptStderr:       true,
+		attrScriptExitCode:     true,
 	}
 }
 func (h handlerScriptResource) attrInternal() map[string]bool {
@@ -188,15 +320,66 @@ func (h handlerScriptResource) changed(rd haschange, attrs map[string]bool) (cha
 	return
 }
 func (h handlerScriptResource) changedAttrInputs(rd haschange) (changed []string) {
-	return h.changed(rd, h.attrInputs())
+	changed = h.changed(rd, h.attrInputs())
+	o, n := rd.GetChange(attrScriptLifecycleCommands)
+	_, oi := h.splitLifecycleCommands(o)
+	_, ni := h.splitLifecycleCommands(n)
+	if !reflect.DeepEqual(oi, ni) {
+		changed = append(changed, attrScriptLifecycleInputSettings)
+	}
+	return
 }
 func (h handlerScriptResource) changedAttrCommands(rd haschange) (changed []string) {
-	return h.changed(rd, h.attrCommands())
+	for _, k := range h.changed(rd, h.attrCommands()) {
+		if k == attrScriptLifecycleCommands {
+			o, n := rd.GetChange(attrScriptLifecycleCommands)
+			oc, _ := h.splitLifecycleCommands(o)
+			nc, _ := h.splitLifecycleCommands(n)
+			if reflect.DeepEqual(oc, nc) {
+				continue // only input settings changed
+			}
+		}
+		changed = append(changed, k)
+	}
+	return
+}
+
+// splitLifecycleCommands separates the commands and their interpreters in lifecycle_commands v from the settings that
+// are inputs of the commands just like the top-level arguments of the same name, i.e. `environment`,
+// `working_directory` and `timeout`. Both are keyed by `<lifecycle>.<argument>`, with unset blocks and empty values
+// normalized so that they can be compared.
+func (h handlerScriptResource) splitLifecycleCommands(v interface{}) (commands, inputs map[string]interface{}) {
+	commands, inputs = map[string]interface{}{}, map[string]interface{}{}
+	lcs := cast.ToSlice(v)
+	if len(lcs) == 0 || lcs[0] == nil {
+		return
+	}
+	lc := cast.ToStringMap(lcs[0])
+	for _, attr := range scriptLifecycles {
+		settings := h.lifecycleSettings(lc, attr)
+		commands[attr] = cast.ToString(lc[attr])
+		commands[attr+"."+attrScriptInterpreter] = strings.Join(cast.ToStringSlice(settings[attrScriptInterpreter]), "\x00")
+		inputs[attr+"."+attrScriptWorkingDirectory] = cast.ToString(settings[attrScriptWorkingDirectory])
+		inputs[attr+"."+attrScriptTimeout] = cast.ToString(settings[attrScriptTimeout])
+		env := cast.ToStringMapString(settings[attrScriptEnvironment])
+		if len(env) == 0 {
+			env = nil
+		}
+		inputs[attr+"."+attrScriptEnvironment] = env
+	}
+	return
 }
 func (h handlerScriptResource) changedAttrInternal(rd haschange) (changed []string) {
 	return h.changed(rd, h.attrInternal())
 }
 
+// imported reports whether the prior state comes from `terraform import`, which only knows the `read` commands.
+func (h handlerScriptResource) imported(rd getchange) bool {
+	o, _ := rd.GetChange(attrScriptLifecycleCommands)
+	lcs := cast.ToSlice(o)
+	return len(lcs) == 0 || cast.ToString(cast.ToStringMap(lcs[0])[attrScriptLifecycleCommandCreate]) == ""
+}
+
 func (h handlerScriptResource) setNewComputed(rd *schema.ResourceDiff) (err error) {
 	for k := range h.attrOutputs() {
 		err = rd.SetNewComputed(k) // assume all computed value will changedAttrInputs
@@ -206,50 +389,201 @@ func (h handlerScriptResource) setNewComputed(rd *schema.ResourceDiff) (err erro
 	}
 	return
 }
+
 func (h handlerScriptResource) newScript(rd *schema.ResourceData, l *linux, attrLifeCycle string) (s *script) {
 	if rd == nil {
 		return
 	}
 
-	lc := cast.ToSlice(rd.Get(attrScriptLifecycleCommands))[0]
+	lc := cast.ToStringMap(cast.ToSlice(rd.Get(attrScriptLifecycleCommands))[0])
 	s = &script{
 		l: l,
 
 		workdir:     cast.ToString(rd.Get(attrScriptWorkingDirectory)),
 		env:         cast.ToStringMapString(rd.Get(attrScriptEnvironment)),
 		interpreter: cast.ToStringSlice(rd.Get(attrScriptInterpreter)),
-		body:        cast.ToStringMapString(lc)[attrLifeCycle],
+		body:        cast.ToString(lc[attrLifeCycle]),
+		mode:        cast.ToString(rd.Get(attrScriptExecutionMode)),
+	}
+	h.applyLifecycleSettings(s, h.lifecycleSettings(lc, attrLifeCycle))
+	s.sensitiveEnv = cast.ToStringMapString(rd.Get(attrScriptSensitiveEnvironment))
+	wo := h.sensitiveEnvironmentWO(rd)
+	for k, v := range wo {
+		s.sensitiveEnv[k] = v
+	}
+	return
+}
+
+// lifecycleSettings returns the settings of the lifecycle command inside lc, which is nil when they are not set.
+func (h handlerScriptResource) lifecycleSettings(lc map[string]interface{}, attrLifeCycle string) map[string]interface{} {
+	settings := cast.ToSlice(lc[attrScriptLifecycleSettings])
+	if len(settings) == 0 || settings[0] == nil {
+		return nil
+	}
+	cmd := cast.ToSlice(cast.ToStringMap(settings[0])[attrLifeCycle])
+	if len(cmd) == 0 || cmd[0] == nil {
+		return nil
+	}
+	return cast.ToStringMap(cmd[0])
+}
+
+// applyLifecycleSettings overrides the top-level arguments of s with the ones set for its lifecycle command.
+// Environment is merged, with the lifecycle specific one taking precedence.
+func (h handlerScriptResource) applyLifecycleSettings(s *script, settings map[string]interface{}) {
+	if i := cast.ToStringSlice(settings[attrScriptInterpreter]); len(i) > 0 {
+		s.interpreter = i
+	}
+	if w := cast.ToString(settings[attrScriptWorkingDirectory]); w != "" {
+		s.workdir = w
 	}
-	for k, v := range cast.ToStringMapString(rd.Get(attrScriptSensitiveEnvironment)) {
+	for k, v := range cast.ToStringMapString(settings[attrScriptEnvironment]) {
 		s.env[k] = v
 	}
+	s.timeout, _ = time.ParseDuration(cast.ToString(settings[attrScriptTimeout])) // validated by schema
+}
+
+func validateScriptTimeout(i interface{}, k string) (warnings []string, errs []error) {
+	v, ok := i.(string)
+	if !ok {
+		errs = append(errs, fmt.Errorf("expected type of %s to be string", k))
+		return
+	}
+	if v == "" {
+		return
+	}
+	if d, err := time.ParseDuration(v); err != nil || d <= 0 {
+		errs = append(errs, fmt.Errorf("invalid duration for %s: %q", k, v))
+	}
+	return
+}
+
+// sensitiveEnvironmentWO decodes the write-only environment, which is only available from the configuration
+// during plan and apply.
+func (h handlerScriptResource) sensitiveEnvironmentWO(rd getrawconfigat) (e map[string]string) {
+	v, _ := rd.GetRawConfigAt(cty.GetAttrPath(attrScriptSensitiveEnvironmentWO))
+	if !v.IsWhollyKnown() || v.IsNull() || !v.Type().Equals(cty.String) {
+		return
+	}
+	_ = json.Unmarshal([]byte(v.AsString()), &e) // validated by schema
+	return
+}
+
+func validateEnvironmentJSON(i interface{}, k string) (warnings []string, errs []error) {
+	v, ok := i.(string)
+	if !ok {
+		errs = append(errs, fmt.Errorf("expected type of %s to be string", k))
+		return
+	}
+	if err := json.Unmarshal([]byte(v), &map[string]string{}); err != nil {
+		errs = append(errs, fmt.Errorf("%s must be a JSON object of strings: %w", k, err))
+	}
+	return
+}
+
+// validateStdinInterpreter ensures that every lifecycle command can be fed through stdin of its interpreter when
+// execution_mode is stdin.
+func (h handlerScriptResource) validateStdinInterpreter(rd *schema.ResourceDiff) (err error) {
+	if !rd.NewValueKnown(attrScriptExecutionMode) || !rd.NewValueKnown(attrScriptInterpreter) ||
+		cast.ToString(rd.Get(attrScriptExecutionMode)) != scriptExecutionModeStdin {
+		return
+	}
+	lcs := cast.ToSlice(rd.Get(attrScriptLifecycleCommands))
+	if len(lcs) == 0 {
+		return
+	}
+	lc := cast.ToStringMap(lcs[0])
+	for _, attr := range scriptLifecycles {
+		s := &script{
+			env:         env{},
+			interpreter: cast.ToStringSlice(rd.Get(attrScriptInterpreter)),
+			body:        cast.ToString(lc[attr]),
+		}
+		if s.body == "" {
+			continue
+		}
+		h.applyLifecycleSettings(s, h.lifecycleSettings(lc, attr))
+		if _, err = s.stdinInterpreter(); err != nil {
+			return fmt.Errorf("%s.0.%s: %w", attrScriptLifecycleCommands, attr, err)
+		}
+	}
 	return
 }
 
-func (h handlerScriptResource) read(ctx context.Context, rd *schema.ResourceData, l *linux) (err error) {
+func (h handlerScriptResource) read(ctx context.Context, rd *schema.ResourceData, l *linux) (res scriptResult, err error) {
 	sc := h.newScript(rd, l, attrScriptLifecycleCommandRead)
-	res, err := sc.exec(ctx)
-	if err != nil {
+	if res, err = sc.exec(ctx); err != nil {
+		return
+	}
+	if err = rd.Set(attrScriptOutput, res.stdout); err != nil {
 		return
 	}
-	if err = rd.Set(attrScriptOutput, res); err != nil {
+	values, err := parseScriptOutput(cast.ToString(rd.Get(attrScriptOutputFormat)), res.stdout)
+	if err != nil {
 		return
 	}
+	err = rd.Set(attrScriptOutputValues, values)
 	return
 }
 
+// setResult records stderr and exit code of the lifecycle commands that ran.
+func (h handlerScriptResource) setResult(rd *schema.ResourceData, res scriptResult) {
+	_ = rd.Set(attrScriptStderr, res.stderr)
+	_ = rd.Set(attrScriptExitCode, res.exitCode)
+}
+
+// warnings surfaces stderr of successful commands when `warn_on_stderr` is enabled.
+func (h handlerScriptResource) warnings(rd *schema.ResourceData, attrLifeCycle string, res scriptResult) (d diag.Diagnostics) {
+	if !cast.ToBool(rd.Get(attrScriptWarnOnStderr)) || strings.TrimSpace(res.stderr) == "" {
+		return
+	}
+	return diag.Diagnostics{{
+		Severity: diag.Warning,
+		Summary:  fmt.Sprintf("`%s` commands wrote to stderr", attrLifeCycle),
+		Detail:   res.stderr,
+	}}
+}
+
+// rereadRequired reports whether the changes to commands affect the output and require `read` commands to be rerun.
+func (h handlerScriptResource) rereadRequired(rd haschange) bool {
+	return rd.HasChange(attrScriptLifecycleCommands+".0."+attrScriptLifecycleCommandRead) ||
+		rd.HasChange(attrScriptLifecycleInputSettings+".0."+attrScriptLifecycleCommandRead+".0."+attrScriptInterpreter) ||
+		rd.HasChange(attrScriptOutputFormat)
+}
+
+// outputChanged compares outputs semantically when they are parsed by output_format, so that e.g. reordered keys
+// of json output are not considered as dirty.
+func (h handlerScriptResource) outputChanged(format, old, new string) bool {
+	if format == scriptOutputFormatRaw || format == "" {
+		return old != new
+	}
+	o, err := parseScriptOutput(format, old)
+	if err != nil {
+		return old != new
+	}
+	n, err := parseScriptOutput(format, new)
+	if err != nil {
+		return true
+	}
+	return !reflect.DeepEqual(o, n)
+}
+
 func (h handlerScriptResource) Read(ctx context.Context, rd *schema.ResourceData, meta interface{}) (d diag.Diagnostics) {
 	_ = rd.Set(attrScriptDirtyOutput, "")
 	_ = rd.Set(attrScriptFaultyOutput, "")
 	old := cast.ToString(rd.Get(attrScriptOutput))
-	defer func() { _ = rd.Set(attrScriptOutput, old) }() // never change output here, since it will be corrected by create or update.
+	oldValues := rd.Get(attrScriptOutputValues)
+	defer func() { // never change output here, since it will be corrected by create or update.
+		_ = rd.Set(attrScriptOutput, old)
+		_ = rd.Set(attrScriptOutputValues, oldValues)
+	}()
 
 	l, err := getLinux(meta.(*linuxPool), rd)
 	if err != nil {
 		return diag.FromErr(err)
 	}
-	err = h.read(ctx, rd, l)
+	res, err := h.read(ctx, rd, l)
 	if errExit := (*remote.ExitError)(nil); errors.As(err, &errExit) {
+		h.setResult(rd, res)
 		_ = rd.Set(attrScriptFaultyOutput, fmt.Sprintf("Faulty output produced:\n\n%s", err))
 		return
 	}
@@ -257,12 +591,15 @@ func (h handlerScriptResource) Read(ctx context.Context, rd *schema.ResourceData
 		return diag.FromErr(err)
 	}
 
+	if cast.ToInt(rd.Get(attrScriptExitCode)) != 0 { // read commands recovered from failing
+		h.setResult(rd, res)
+	}
 	new := cast.ToString(rd.Get(attrScriptOutput))
-	if old != new {
+	if h.outputChanged(cast.ToString(rd.Get(attrScriptOutputFormat)), old, new) {
 		_ = rd.Set(attrScriptDirtyOutput, fmt.Sprintf("Dirty output detected:\n\n%s", new))
 	}
 
-	return
+	return h.warnings(rd, attrScriptLifecycleCommandRead, res)
 }
 
 func (h handlerScriptResource) Create(ctx context.Context, rd *schema.ResourceData, meta interface{}) (d diag.Diagnostics) {
@@ -271,9 +608,12 @@ func (h handlerScriptResource) Create(ctx context.Context, rd *schema.ResourceDa
 		return diag.FromErr(err)
 	}
 	sc := h.newScript(rd, l, attrScriptLifecycleCommandCreate)
-	if _, err := sc.exec(ctx); err != nil {
+	res, err := sc.exec(ctx)
+	if err != nil {
 		return diag.FromErr(err)
 	}
+	h.setResult(rd, res)
+	d = h.warnings(rd, attrScriptLifecycleCommandCreate, res)
 
 	id, err := uuid.NewRandom()
 	if err != nil {
@@ -281,10 +621,10 @@ func (h handlerScriptResource) Create(ctx context.Context, rd *schema.ResourceDa
 	}
 	rd.SetId(id.String())
 
-	if err := h.read(ctx, rd, l); err != nil {
-		return diag.FromErr(err)
+	if res, err = h.read(ctx, rd, l); err != nil {
+		return append(d, diag.FromErr(err)...)
 	}
-	return
+	return append(d, h.warnings(rd, attrScriptLifecycleCommandRead, res)...)
 }
 
 // WARN: see https://github.com/hashicorp/terraform-plugin-sdk/issues/476
@@ -308,12 +648,14 @@ func (h handlerScriptResource) UpdateCommands(ctx context.Context, rd *schema.Re
 		return diag.FromErr(err)
 	}
 
-	if rd.HasChange(attrScriptLifecycleCommands + ".0." + attrScriptLifecycleCommandRead) {
-		err := h.read(ctx, rd, l)
+	if h.rereadRequired(rd) || h.imported(rd) {
+		res, err := h.read(ctx, rd, l)
 		if err != nil {
 			_ = h.restoreOldResourceData(rd, nil)
 			return diag.FromErr(err)
 		}
+		h.setResult(rd, res)
+		return h.warnings(rd, attrScriptLifecycleCommandRead, res)
 	}
 
 	return
@@ -331,19 +673,22 @@ func (h handlerScriptResource) Update(ctx context.Context, rd *schema.ResourceDa
 	sc := h.newScript(rd, l, attrScriptLifecycleCommandUpdate)
 	oldOutput := cast.ToString(rd.Get(attrScriptOutput))
 	sc.stdin = strings.NewReader(oldOutput)
-	if _, err := sc.exec(ctx); err != nil {
+	res, err := sc.exec(ctx)
+	if err != nil {
 		_ = h.restoreOldResourceData(rd, nil)
 		return diag.FromErr(err)
 	}
+	h.setResult(rd, res)
+	d = h.warnings(rd, attrScriptLifecycleCommandUpdate, res)
 
-	if err := h.read(ctx, rd, l); err != nil {
-		return diag.FromErr(err)
+	if res, err = h.read(ctx, rd, l); err != nil {
+		return append(d, diag.FromErr(err)...)
 	}
-	return
+	return append(d, h.warnings(rd, attrScriptLifecycleCommandRead, res)...)
 }
 
 func (h handlerScriptResource) Delete(ctx context.Context, rd *schema.ResourceData, meta interface{}) (d diag.Diagnostics) {
-	if cast.ToString(rd.Get(attrScriptFaultyOutput)) != "" {
+	if cast.ToString(rd.Get(attrScriptFaultyOutput)) != "" || h.imported(rd) {
 		return
 	}
 	l, err := getLinux(meta.(*linuxPool), rd)
@@ -351,16 +696,24 @@ func (h handlerScriptResource) Delete(ctx context.Context, rd *schema.ResourceDa
 		return diag.FromErr(err)
 	}
 	sc := h.newScript(rd, l, attrScriptLifecycleCommandDelete)
-	if _, err := sc.exec(ctx); err != nil {
+	res, err := sc.exec(ctx)
+	if err != nil {
 		return diag.FromErr(err)
 	}
-	return
+	return h.warnings(rd, attrScriptLifecycleCommandDelete, res)
 }
 
 func (h handlerScriptResource) CustomizeDiff(c context.Context, rd *schema.ResourceDiff, meta interface{}) (err error) {
+	if err = h.validateStdinInterpreter(rd); err != nil {
+		return
+	}
 	if rd.Id() == "" {
 		return // no state
 	}
+	if h.imported(rd) {
+		_ = h.setNewComputed(rd) // output will be read again by the configured `read` command
+		return
+	}
 
 	if cmd := h.changedAttrCommands(rd); len(cmd) > 0 {
 		if fbd := h.changedAttrInputs(rd); len(fbd) > 0 {
@@ -368,7 +721,7 @@ func (h handlerScriptResource) CustomizeDiff(c context.Context, rd *schema.Resou
 				strings.Join(cmd, ","), strings.Join(fbd, ","))
 		}
 
-		if rd.HasChange(attrScriptLifecycleCommands + ".0." + attrScriptLifecycleCommandRead) {
+		if h.rereadRequired(rd) {
 			_ = h.setNewComputed(rd) // assume all computed will change
 		}
 		return // updated commands. let Update handle it.
@@ -386,7 +739,10 @@ func (h handlerScriptResource) CustomizeDiff(c context.Context, rd *schema.Resou
 		return // updateable
 	}
 
-	for _, key := range h.changed(rd, h.attrs(h.attrInputs(), h.attrInternal())) {
+	for _, key := range append(h.changedAttrInputs(rd), h.changedAttrInternal(rd)...) {
+		if key == attrScriptLifecycleInputSettings {
+			key = attrScriptLifecycleCommands
+		}
 		err = rd.ForceNew(key)
 		if err != nil {
 			return
@@ -395,6 +751,45 @@ func (h handlerScriptResource) CustomizeDiff(c context.Context, rd *schema.Resou
 	return
 }
 
+// Import runs the `read` commands given as import ID, optionally prefixed with the connection and a colon, to seed
+// `output`. Only `id=...` connections are accepted, since a plain connection id can't be told apart from commands.
+func (h handlerScriptResource) Import(ctx context.Context, rd *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	conn, read := "", rd.Id()
+	if strings.HasPrefix(read, attrProviderID+"=") {
+		conn, read = parseImportID(read)
+	}
+	if strings.TrimSpace(read) == "" {
+		return nil, fmt.Errorf("expected import ID in the form of `read` commands, optionally prefixed with `connection:`, got %q", rd.Id())
+	}
+
+	if err := importDefaults(rd, schemaScriptResource); err != nil {
+		return nil, err
+	}
+	if err := importProviderOverride(meta.(*linuxPool), rd, attrScriptProviderOverride, conn); err != nil {
+		return nil, err
+	}
+	lc := map[string]interface{}{attrScriptLifecycleCommandRead: read}
+	if err := rd.Set(attrScriptLifecycleCommands, []interface{}{lc}); err != nil {
+		return nil, err
+	}
+	l, err := getLinux(meta.(*linuxPool), rd)
+	if err != nil {
+		return nil, err
+	}
+	res, err := h.read(ctx, rd, l)
+	if err != nil {
+		return nil, err
+	}
+	h.setResult(rd, res)
+
+	id, err := uuid.NewRandom()
+	if err != nil {
+		return nil, err
+	}
+	rd.SetId(id.String())
+	return []*schema.ResourceData{rd}, nil
+}
+
 func scriptResource() *schema.Resource {
 	var h handlerScriptResource
 	return &schema.Resource{
@@ -404,5 +799,8 @@ func scriptResource() *schema.Resource {
 		UpdateContext: h.Update,
 		DeleteContext: h.Delete,
 		CustomizeDiff: h.CustomizeDiff,
+		Importer: &schema.ResourceImporter{
+			StateContext: h.Import,
+		},
 	}
 }
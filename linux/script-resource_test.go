@@ -3,11 +3,13 @@ package linux
 import (
 	"fmt"
 	"regexp"
+	"strings"
 	"testing"
 
 	"github.com/MakeNowJust/heredoc"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 	"github.com/stretchr/testify/require"
 )
 
@@ -133,6 +135,59 @@ func testAccLinuxScriptBasicConfig(t *testing.T, conf tfConf) (s string) {
 	return
 }
 
+func TestAccLinuxScriptImport(t *testing.T) {
+	conf := tfConf{
+		Provider: testAccProvider,
+		Script: tfScript{
+			Interpreter: tfList{`"sh"`, `"-c"`},
+			Environment: tfmap{
+				"FILE":    fmt.Sprintf(`"/tmp/linux/%s"`, acctest.RandString(16)),
+				"CONTENT": `"test"`,
+			},
+		},
+	}
+	updated := conf.Copy(func(tc *tfConf) {
+		tc.Script.Environment.With("FILE", fmt.Sprintf(`"/tmp/linux/%s"`, acctest.RandString(16)))
+	})
+
+	resource.Test(t, resource.TestCase{
+		ExternalProviders: map[string]resource.ExternalProvider{"null": {}},
+		PreCheck:          testAccPreCheckConnection(t),
+		Providers:         testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccLinuxScriptBasicConfig(t, conf),
+			},
+			{
+				Config:             testAccLinuxScriptBasicConfig(t, conf),
+				ResourceName:       "linux_script.script",
+				ImportState:        true,
+				ImportStateId:      "echo " + conf.Script.Environment["FILE"],
+				ImportStatePersist: true,
+				ImportStateCheck: func(is []*terraform.InstanceState) error {
+					if len(is) != 1 {
+						return fmt.Errorf("expected 1 imported state, got %d", len(is))
+					}
+					expected := strings.Trim(conf.Script.Environment["FILE"], `"`) + "\n"
+					if output := is[0].Attributes[attrScriptOutput]; output != expected {
+						return fmt.Errorf("imported %s is %q, expected %q", attrScriptOutput, output, expected)
+					}
+					return nil
+				},
+			},
+			{
+				Config: testAccLinuxScriptBasicConfig(t, conf),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("linux_script.script", attrScriptOutput, strings.Trim(conf.Script.Environment["FILE"], `"`)+"\n"),
+				),
+			},
+			{
+				Config: testAccLinuxScriptBasicConfig(t, updated),
+			},
+		},
+	})
+}
+
 func TestAccLinuxScriptNoUpdate(t *testing.T) {
 	conf1 := tfConf{
 		Provider: testAccProvider,
@@ -676,3 +731,396 @@ func testAccLinuxScriptComputedConfig(t *testing.T, conf tfConf) (s string) {
 	require.NoError(t, err, "compile template failed")
 	return
 }
+
+func TestAccLinuxScriptSensitiveEnvironmentWO(t *testing.T) {
+	conf1 := tfConf{
+		Provider: testAccProvider,
+		Extra: tfmap{
+			"file":    fmt.Sprintf(`"/tmp/linux/%s"`, acctest.RandString(16)),
+			"secret":  `"one"`,
+			"version": "1",
+		},
+	}
+	conf2 := conf1.Copy(func(tc *tfConf) {
+		tc.Extra.With("secret", `"two"`)
+	})
+	conf3 := conf2.Copy(func(tc *tfConf) {
+		tc.Extra.With("version", "2")
+	})
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  testAccPreCheckConnection(t),
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccLinuxScriptSensitiveEnvironmentWOConfig(t, conf1),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckNoResourceAttr("linux_script.script", attrScriptSensitiveEnvironmentWO),
+					resource.TestCheckResourceAttr("linux_script.script", attrScriptOutput,
+						"7692c3ad3540bb803c020b3aee66cd8887123234ea0c6e7143c0add73ff431ed\n"),
+				),
+			},
+			{
+				Config: testAccLinuxScriptSensitiveEnvironmentWOConfig(t, conf2),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("linux_script.script", attrScriptOutput,
+						"7692c3ad3540bb803c020b3aee66cd8887123234ea0c6e7143c0add73ff431ed\n"),
+				),
+			},
+			{
+				Config: testAccLinuxScriptSensitiveEnvironmentWOConfig(t, conf3),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckNoResourceAttr("linux_script.script", attrScriptSensitiveEnvironmentWO),
+					resource.TestCheckResourceAttr("linux_script.script", attrScriptOutput,
+						"3fc4ccfe745870e2c0d99f71f30ff0656c8dedd41cc1d7d3d376b0dbe685e2f3\n"),
+				),
+			},
+		},
+	})
+}
+
+func testAccLinuxScriptSensitiveEnvironmentWOConfig(t *testing.T, conf tfConf) (s string) {
+	tf := heredoc.Doc(`
+		provider "linux" {
+		    alias = "test"
+		    {{- .Provider.Serialize | nindent 4 }}
+		}
+
+		resource "linux_script" "script" {
+		    provider = linux.test
+
+		    lifecycle_commands {
+		        create = <<-EOF
+		            mkdir -p "$(dirname "$FILE")" && printf %s "$SECRET" | sha256sum | cut -d ' ' -f 1 > "$FILE"
+		        EOF
+		        read = <<-EOF
+		            cat "$FILE"
+		        EOF
+		        update = <<-EOF
+		            printf %s "$SECRET" | sha256sum | cut -d ' ' -f 1 > "$FILE"
+		        EOF
+		        delete = <<-EOF
+		            rm "$FILE"
+		        EOF
+		    }
+		    environment = {
+		        FILE = {{ .Extra.file }}
+		    }
+		    sensitive_environment_wo = jsonencode({
+		        SECRET = {{ .Extra.secret }}
+		    })
+		    sensitive_environment_wo_version = {{ .Extra.version }}
+		}
+	`)
+	s, err := conf.compile(tf)
+	t.Log(s)
+	require.NoError(t, err, "compile template failed")
+	return
+}
+
+func TestAccLinuxScriptSensitiveEnvironmentHidden(t *testing.T) {
+	secret := acctest.RandString(16)
+	conf := tfConf{
+		Provider: testAccProvider,
+		Script: tfScript{
+			Environment: tfmap{
+				"FILE":   fmt.Sprintf(`"/tmp/linux/%s"`, acctest.RandString(16)),
+				"SECRET": `"overridden"`,
+			},
+			SensitiveEnvironment: tfmap{
+				"SECRET": fmt.Sprintf(`"%s"`, secret),
+			},
+			LifecycleCommands: tfmap{
+				"create": heredoc.Doc(`
+					tr '\0' ' ' < /proc/$PPID/cmdline | grep -q "$SECRET" && exit 101
+					mkdir -p "$(dirname "$FILE")" && printf %s "$SECRET" > "$FILE"
+				`),
+				"read":   `"cat \"$FILE\""`,
+				"delete": `"rm \"$FILE\""`,
+			},
+		},
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  testAccPreCheckConnection(t),
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccLinuxScriptComputedConfig(t, conf),
+				Check:  resource.TestCheckResourceAttr("linux_script.linux_script", attrScriptOutput, secret),
+			},
+		},
+	})
+}
+
+func TestAccLinuxScriptExecutionModeStdin(t *testing.T) {
+	conf1 := tfConf{
+		Provider: testAccProvider,
+		Script: tfScript{
+			ExecutionMode: `"stdin"`,
+			Environment: tfmap{
+				"FILE":    fmt.Sprintf(`"/tmp/linux/%s"`, acctest.RandString(16)),
+				"CONTENT": `"first"`,
+			},
+			LifecycleCommands: tfmap{
+				"create": heredoc.Doc(`
+					#!/bin/sh
+					mkdir -p "$(dirname "$FILE")" && printf %s "$CONTENT" > "$FILE"
+				`),
+				"read": `"cat \"$FILE\""`,
+				"update": heredoc.Doc(`
+					[ "$(cat "$LINUX_SCRIPT_PREVIOUS_OUTPUT_FILE")" = "$(cat "$FILE")" ] || exit 101
+					printf %s "$CONTENT" > "$FILE"
+				`),
+				"delete": `"rm \"$FILE\""`,
+			},
+		},
+	}
+	conf2 := conf1.Copy(func(tc *tfConf) {
+		tc.Script.Environment.With("CONTENT", `"second"`)
+	})
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  testAccPreCheckConnection(t),
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccLinuxScriptComputedConfig(t, conf1),
+				Check:  resource.TestCheckResourceAttr("linux_script.linux_script", attrScriptOutput, "first"),
+			},
+			{
+				Config: testAccLinuxScriptComputedConfig(t, conf2),
+				Check:  resource.TestCheckResourceAttr("linux_script.linux_script", attrScriptOutput, "second"),
+			},
+		},
+	})
+}
+
+func TestAccLinuxScriptOutputFormat(t *testing.T) {
+	conf1 := tfConf{
+		Provider: testAccProvider,
+		Script: tfScript{
+			OutputFormat: `"json"`,
+			Environment: tfmap{
+				"FILE":    fmt.Sprintf(`"/tmp/linux/%s"`, acctest.RandString(16)),
+				"CONTENT": `"first"`,
+			},
+			LifecycleCommands: tfmap{
+				"create": heredoc.Doc(`
+					mkdir -p "$(dirname "$FILE")" && printf %s "$CONTENT" > "$FILE"
+				`),
+				"read": heredoc.Doc(`
+					printf '{"content": "%s", "nested": {"number": 1}}' "$(cat "$FILE")"
+				`),
+				"update": heredoc.Doc(`
+					printf %s "$CONTENT" > "$FILE"
+				`),
+				"delete": `"rm \"$FILE\""`,
+			},
+		},
+	}
+	conf2 := conf1.Copy(func(tc *tfConf) {
+		tc.Script.OutputFormat = `"kv"`
+		tc.Script.LifecycleCommands.With("read", heredoc.Doc(`
+			echo "# comment"
+			echo "content = $(cat "$FILE")"
+		`))
+	})
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  testAccPreCheckConnection(t),
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccLinuxScriptComputedConfig(t, conf1),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("linux_script.linux_script", attrScriptOutputValues+".content", "first"),
+					resource.TestCheckResourceAttr("linux_script.linux_script", attrScriptOutputValues+".nested", `{"number":1}`),
+				),
+			},
+			{
+				Config: testAccLinuxScriptComputedConfig(t, conf2),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("linux_script.linux_script", attrScriptOutputValues+".%", "1"),
+					resource.TestCheckResourceAttr("linux_script.linux_script", attrScriptOutputValues+".content", "first"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccLinuxScriptOutputFormatMismatch(t *testing.T) {
+	conf1 := tfConf{
+		Provider: testAccProvider,
+		Script: tfScript{
+			OutputFormat: `"kv"`,
+			Environment: tfmap{
+				"FILE":    fmt.Sprintf(`"/tmp/linux/%s"`, acctest.RandString(16)),
+				"CONTENT": `"first"`,
+				"BREAK":   `""`,
+			},
+			LifecycleCommands: tfmap{
+				"create": heredoc.Doc(`
+					mkdir -p "$(dirname "$FILE")" && printf %s "$CONTENT" > "$FILE"
+				`),
+				"read": heredoc.Doc(`
+					if [ -s "$FILE.break" ] && [ "$(cat "$FILE.break")" -eq 0 ]; then
+					  rm "$FILE.break" && echo "broken"
+					else
+					  [ ! -s "$FILE.break" ] || echo 0 > "$FILE.break"
+					  echo "content=$(cat "$FILE")"
+					fi
+				`),
+				"update": heredoc.Doc(`
+					printf %s "$CONTENT" > "$FILE"
+					[ -z "$BREAK" ] || echo 1 > "$FILE.break"
+				`),
+				"delete": `"rm \"$FILE\""`,
+			},
+		},
+	}
+	conf2 := conf1.Copy(func(tc *tfConf) {
+		tc.Script.Environment.With("CONTENT", `"second"`).With("BREAK", `"true"`)
+	})
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  testAccPreCheckConnection(t),
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccLinuxScriptComputedConfig(t, conf1),
+				Check:  resource.TestCheckResourceAttr("linux_script.linux_script", attrScriptOutputValues+".content", "first"),
+			},
+			{
+				Config:      testAccLinuxScriptComputedConfig(t, conf2),
+				ExpectError: regexp.MustCompile(`output doesn't match output_format`),
+			},
+			{
+				Config:   testAccLinuxScriptComputedConfig(t, conf2),
+				PlanOnly: true,
+			},
+		},
+	})
+}
+
+func TestAccLinuxScriptStderr(t *testing.T) {
+	conf1 := tfConf{
+		Provider: testAccProvider,
+		Script: tfScript{
+			WarnOnStderr: `true`,
+			Environment: tfmap{
+				"FILE":    fmt.Sprintf(`"/tmp/linux/%s"`, acctest.RandString(16)),
+				"CONTENT": `"first"`,
+			},
+			LifecycleCommands: tfmap{
+				"create": heredoc.Doc(`
+					mkdir -p "$(dirname "$FILE")" && printf %s "$CONTENT" > "$FILE"
+					echo "created" >&2
+				`),
+				"read": heredoc.Doc(`
+					cat "$FILE"
+					echo "deprecated: $CONTENT" >&2
+				`),
+				"update": heredoc.Doc(`
+					printf %s "$CONTENT" > "$FILE"
+					echo "updated" >&2
+				`),
+				"delete": `"rm \"$FILE\""`,
+			},
+		},
+	}
+	conf2 := conf1.Copy(func(tc *tfConf) {
+		tc.Script.Environment.With("CONTENT", `"second"`)
+	})
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  testAccPreCheckConnection(t),
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccLinuxScriptComputedConfig(t, conf1),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("linux_script.linux_script", attrScriptOutput, "first"),
+					resource.TestCheckResourceAttr("linux_script.linux_script", attrScriptStderr, "created\n"),
+					resource.TestCheckResourceAttr("linux_script.linux_script", attrScriptExitCode, "0"),
+				),
+			},
+			{
+				Config: testAccLinuxScriptComputedConfig(t, conf2),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("linux_script.linux_script", attrScriptOutput, "second"),
+					resource.TestCheckResourceAttr("linux_script.linux_script", attrScriptStderr, "updated\n"),
+					resource.TestCheckResourceAttr("linux_script.linux_script", attrScriptExitCode, "0"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccLinuxScriptLifecycleSettings(t *testing.T) {
+	dir := fmt.Sprintf(`"/tmp/linux/%s"`, acctest.RandString(16))
+	conf1 := tfConf{
+		Provider: testAccProvider,
+		Script: tfScript{
+			Environment: tfmap{
+				"FILE":    `"content"`,
+				"CONTENT": `"first"`,
+			},
+			LifecycleCommands: tfmap{
+				"create": heredoc.Doc(`
+					printf %s "$CONTENT" > "$FILE"
+				`),
+				"read": heredoc.Doc(`
+					printf '%s%s' "$(cat "$FILE")" "$SUFFIX"
+				`),
+				"update": heredoc.Doc(`
+					printf %s "$CONTENT" > "$FILE"
+				`),
+				"delete": `"rm \"$FILE\""`,
+			},
+			LifecycleSettings: map[string]tfmap{
+				attrScriptLifecycleCommandCreate: {attrScriptWorkingDirectory: dir},
+				attrScriptLifecycleCommandRead: {
+					attrScriptWorkingDirectory: dir,
+					attrScriptInterpreter:      `["sh"]`,
+					attrScriptEnvironment:      `{ SUFFIX = "-read" }`,
+					attrScriptTimeout:          `"30s"`,
+				},
+				attrScriptLifecycleCommandUpdate: {attrScriptWorkingDirectory: dir},
+				attrScriptLifecycleCommandDelete: {attrScriptWorkingDirectory: dir},
+			},
+		},
+	}
+	conf2 := conf1.Copy(func(tc *tfConf) {
+		tc.Script.Environment.With("CONTENT", `"second"`)
+	})
+	conf3 := conf2.Copy(func(tc *tfConf) { // runs update, just like changing the top-level environment
+		tc.Script.LifecycleSettings[attrScriptLifecycleCommandUpdate].With(attrScriptEnvironment, `{ CONTENT = "third" }`)
+	})
+	conf4 := conf3.Copy(func(tc *tfConf) {
+		tc.Script.LifecycleSettings[attrScriptLifecycleCommandRead].With(attrScriptEnvironment, `{ SUFFIX = "-reread" }`)
+	})
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  testAccPreCheckConnection(t),
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccLinuxScriptComputedConfig(t, conf1),
+				Check:  resource.TestCheckResourceAttr("linux_script.linux_script", attrScriptOutput, "first-read"),
+			},
+			{
+				Config: testAccLinuxScriptComputedConfig(t, conf2),
+				Check:  resource.TestCheckResourceAttr("linux_script.linux_script", attrScriptOutput, "second-read"),
+			},
+			{
+				Config: testAccLinuxScriptComputedConfig(t, conf3),
+				Check:  resource.TestCheckResourceAttr("linux_script.linux_script", attrScriptOutput, "third-read"),
+			},
+			{
+				Config: testAccLinuxScriptComputedConfig(t, conf4),
+				Check:  resource.TestCheckResourceAttr("linux_script.linux_script", attrScriptOutput, "third-reread"),
+			},
+		},
+	})
+}
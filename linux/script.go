@@ -4,11 +4,17 @@ import (
 	"bufio"
 	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	"al.essio.dev/pkg/shellescape"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform/communicator/remote"
 )
 
@@ -39,17 +45,68 @@ func (e env) multiline() string {
 	return e.serialize("\n")
 }
 
+const (
+	scriptExecutionModeUpload = "upload"
+	scriptExecutionModeStdin  = "stdin"
+)
+
+var scriptExecutionModes = []string{scriptExecutionModeUpload, scriptExecutionModeStdin}
+
+// envPreviousOutputFile holds the path of a file containing the previous output when the script body
+// occupies stdin.
+const envPreviousOutputFile = "LINUX_SCRIPT_PREVIOUS_OUTPUT_FILE"
+
 type script struct {
 	l *linux
 
-	workdir     string
-	env         env
-	interpreter []string
-	body        string
+	workdir      string
+	env          env
+	sensitiveEnv env
+	interpreter  []string
+	body         string
+	mode         string
+	timeout      time.Duration
 
 	stdin io.Reader
 }
 
+// scriptResult holds what a script produced. exitCode is -1 when the script didn't run to completion.
+type scriptResult struct {
+	stdout   string
+	stderr   string
+	exitCode int
+}
+
+// scriptLogWriter streams each line written by a running script into tflog.
+type scriptLogWriter struct {
+	ctx    context.Context
+	stream string
+	buf    bytes.Buffer
+}
+
+func (w *scriptLogWriter) Write(p []byte) (n int, err error) {
+	w.buf.Write(p)
+	for {
+		i := bytes.IndexByte(w.buf.Bytes(), '\n')
+		if i < 0 {
+			break
+		}
+		w.log(string(w.buf.Next(i + 1)[:i]))
+	}
+	return len(p), nil
+}
+
+func (w *scriptLogWriter) flush() {
+	if w.buf.Len() > 0 {
+		w.log(w.buf.String())
+		w.buf.Reset()
+	}
+}
+
+func (w *scriptLogWriter) log(line string) {
+	tflog.Debug(w.ctx, "script "+w.stream, map[string]interface{}{"line": line})
+}
+
 func (sc *script) scriptForUpload() (io.Reader, error) {
 	if len(sc.interpreter) == 0 {
 		return strings.NewReader(sc.body), nil
@@ -81,28 +138,194 @@ func (sc *script) upload(ctx context.Context) (path string, err error) {
 	return
 }
 
-func (sc *script) exec(ctx context.Context) (res string, err error) {
-	path, err := sc.upload(ctx)
-	if err != nil {
+// errScriptStdinInterpreter is returned when the script body can't be fed to the interpreter through stdin.
+var errScriptStdinInterpreter = errors.New("interpreter can't read the script from stdin")
+
+// scriptProgramFlags are the flags that make an interpreter take its program from the following argument.
+var scriptProgramFlags = []string{"-c", "--command", "-Command", "--eval"}
+
+// stdinInterpreter returns the command reading body from stdin, which is either the interpreter, the shebang of the
+// body, or sh. The shebang is split the way the kernel does, i.e. into the interpreter and a single optional argument,
+// so that `#!/usr/bin/env -S` keeps working. A trailing `-c` is dropped, since `sh -c` reads its commands from stdin
+// when left without an argument.
+func stdinInterpreter(interpreter []string, body string) (cmd []string, err error) {
+	cmd = interpreter
+	if len(cmd) == 0 {
+		cmd = []string{"sh"}
+		if line, _, _ := strings.Cut(body, "\n"); strings.HasPrefix(line, "#!") {
+			path, arg := strings.TrimSpace(line[2:]), ""
+			if i := strings.IndexAny(path, " \t"); i >= 0 {
+				path, arg = path[:i], strings.TrimSpace(path[i:])
+			}
+			if path != "" {
+				cmd = []string{path}
+			}
+			if path != "" && arg != "" {
+				cmd = append(cmd, arg)
+			}
+		}
+	}
+	if cmd[len(cmd)-1] == "-c" {
+		cmd = cmd[:len(cmd)-1]
+	}
+	if len(cmd) == 0 {
+		return nil, fmt.Errorf("%w: empty interpreter", errScriptStdinInterpreter)
+	}
+	for _, arg := range cmd[1:] {
+		if slices.Contains(scriptProgramFlags, arg) {
+			return nil, fmt.Errorf("%w: %q takes the program from the argument of %s", errScriptStdinInterpreter,
+				strings.Join(cmd, " "), arg)
+		}
+	}
+	return
+}
+
+func (sc *script) stdinInterpreter() ([]string, error) {
+	return stdinInterpreter(sc.interpreter, sc.body)
+}
+
+// mkPrivateDir creates a directory only accessible by the connecting user.
+func (sc *script) mkPrivateDir(ctx context.Context) (dir string, err error) {
+	stdout := new(bytes.Buffer)
+	if err = sc.l.exec(ctx, &remote.Cmd{Command: "mktemp -d", Stdout: stdout}); err != nil {
 		return
 	}
-	defer func() { _ = sc.l.remove(ctx, path, "") }()
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+func (sc *script) exec(ctx context.Context) (res scriptResult, err error) {
+	res.exitCode = -1
+	var dir string
+	if len(sc.sensitiveEnv) > 0 || (sc.mode == scriptExecutionModeStdin && sc.stdin != nil) {
+		if dir, err = sc.mkPrivateDir(ctx); err != nil {
+			return
+		}
+		defer func() { _ = sc.l.remove(ctx, dir, deleteRemove{}) }()
+	}
+
+	plain := env{}
+	for k, v := range sc.env {
+		if _, ok := sc.sensitiveEnv[k]; !ok {
+			plain[k] = v // sensitive environment takes precedence
+		}
+	}
+	var command []string
+	var stdin io.Reader
+	switch sc.mode {
+	case scriptExecutionModeStdin:
+		if sc.stdin != nil {
+			if err = sc.l.upload(ctx, dir+"/previous-output", sc.stdin); err != nil {
+				return
+			}
+			plain[envPreviousOutputFile] = dir + "/previous-output"
+		}
+		if command, err = sc.stdinInterpreter(); err != nil {
+			return
+		}
+		stdin = strings.NewReader(sc.body)
+
+	default:
+		path, err := sc.upload(ctx)
+		if err != nil {
+			return res, err
+		}
+		defer func() { _ = sc.l.remove(ctx, path, deleteRemove{}) }()
+		command = append(append([]string{}, sc.interpreter...), path)
+		stdin = sc.stdin
+	}
+	if sc.timeout > 0 {
+		// enforced on the remote host since the communicator can't interrupt running commands
+		secs := strconv.FormatFloat(sc.timeout.Seconds(), 'f', -1, 64) + "s"
+		command = append([]string{"timeout", secs}, command...)
+	}
+	run := fmt.Sprintf(`%s %s`, plain.inline(), shellescape.QuoteCommand(command))
+
+	if len(sc.sensitiveEnv) > 0 {
+		envFile := dir + "/env"
+		if err = sc.l.upload(ctx, envFile, strings.NewReader(sc.sensitiveEnv.multiline()+"\n")); err != nil {
+			return
+		}
+		// sourced with auto export, then removed before the script starts
+		run = fmt.Sprintf(`( %s && set -a && . %s && set +a && %s && %s )`,
+			shellescape.QuoteCommand([]string{"chmod", "600", envFile}), shellescape.Quote(envFile),
+			shellescape.QuoteCommand([]string{"rm", "-f", envFile}), run)
+	}
 
-	cmd := fmt.Sprintf(`{ %s && %s && %s %s ;}`,
+	cmd := fmt.Sprintf(`{ %s && %s && %s ;}`,
 		shellescape.QuoteCommand([]string{"mkdir", "-p", sc.workdir}),
 		shellescape.QuoteCommand([]string{"cd", sc.workdir}),
-		sc.env.inline(), shellescape.QuoteCommand(append(sc.interpreter, path)),
+		run,
 	)
 	stdout, stderr := new(bytes.Buffer), new(bytes.Buffer)
+	stdoutLog := &scriptLogWriter{ctx: ctx, stream: "stdout"}
+	stderrLog := &scriptLogWriter{ctx: ctx, stream: "stderr"}
 	err = sc.l.exec(ctx, &remote.Cmd{
 		Command: cmd,
-		Stdin:   sc.stdin,
-		Stdout:  stdout,
-		Stderr:  stderr,
+		Stdin:   stdin,
+		Stdout:  io.MultiWriter(stdout, stdoutLog),
+		Stderr:  io.MultiWriter(stderr, stderrLog),
 	})
+	stdoutLog.flush()
+	stderrLog.flush()
+	res.stdout, res.stderr = stdout.String(), stderr.String()
+	if errExit := (*remote.ExitError)(nil); errors.As(err, &errExit) && errExit.ExitStatus != 0 {
+		res.exitCode = errExit.ExitStatus
+	}
 	if err != nil {
 		err = fmt.Errorf("stderr: %s\nerror: %w", stderr, err)
 		return
 	}
-	return stdout.String(), nil
+	res.exitCode = 0
+	return
+}
+
+const (
+	scriptOutputFormatRaw  = "raw"
+	scriptOutputFormatJSON = "json"
+	scriptOutputFormatKV   = "kv"
+)
+
+var (
+	scriptOutputFormats = []string{scriptOutputFormatRaw, scriptOutputFormatJSON, scriptOutputFormatKV}
+
+	errScriptOutputFormat = errors.New("output doesn't match output_format")
+)
+
+// parseScriptOutput parses the output of `read` commands into a flat map. JSON output must be an object whose
+// non-string values are kept as compact JSON, except null which becomes empty string. KV output consists of
+// KEY=VALUE lines, ignoring empty and `#` lines.
+func parseScriptOutput(format, output string) (values map[string]string, err error) {
+	switch format {
+	case scriptOutputFormatJSON:
+		m := map[string]json.RawMessage{}
+		if err = json.Unmarshal([]byte(output), &m); err != nil {
+			return nil, fmt.Errorf("%w: %s", errScriptOutputFormat, err)
+		}
+		values = make(map[string]string, len(m))
+		for k, raw := range m {
+			var s string
+			if err := json.Unmarshal(raw, &s); err == nil {
+				values[k] = s
+				continue
+			}
+			compact := new(bytes.Buffer)
+			_ = json.Compact(compact, raw) // already validated
+			values[k] = compact.String()
+		}
+
+	case scriptOutputFormatKV:
+		values = map[string]string{}
+		for i, line := range strings.Split(output, "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			k, v, ok := strings.Cut(line, "=")
+			if !ok || strings.TrimSpace(k) == "" {
+				return nil, fmt.Errorf("%w: line %d is not in KEY=VALUE form", errScriptOutputFormat, i+1)
+			}
+			values[strings.TrimSpace(k)] = strings.TrimSpace(v)
+		}
+	}
+	return
 }
@@ -0,0 +1,42 @@
+package linux
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStdinInterpreter(t *testing.T) {
+	tests := []struct {
+		name        string
+		interpreter []string
+		body        string
+		expected    []string
+		err         bool
+	}{
+		{name: "default", body: "echo hello", expected: []string{"sh"}},
+		{name: "interpreter", interpreter: []string{"bash", "-e"}, body: "#!/bin/zsh\necho hello", expected: []string{"bash", "-e"}},
+		{name: "interpreter with trailing -c", interpreter: []string{"sh", "-c"}, body: "echo hello", expected: []string{"sh"}},
+		{name: "interpreter with program", interpreter: []string{"sh", "-c", "echo hello"}, body: "echo hello", err: true},
+		{name: "interpreter with eval", interpreter: []string{"node", "--eval"}, body: "console.log(1)", err: true},
+		{name: "interpreter of only -c", interpreter: []string{"-c"}, body: "echo hello", err: true},
+		{name: "shebang", body: "#!/usr/bin/python3\nprint(1)", expected: []string{"/usr/bin/python3"}},
+		{name: "shebang with argument", body: "#!/bin/bash -e\necho hello", expected: []string{"/bin/bash", "-e"}},
+		{name: "shebang with env -S", body: "#!/usr/bin/env -S bash -e\necho hello", expected: []string{"/usr/bin/env", "-S bash -e"}},
+		{name: "shebang with whitespaces", body: "#! /bin/bash\t-e \r\necho hello", expected: []string{"/bin/bash", "-e"}},
+		{name: "shebang with trailing -c", body: "#!/bin/sh -c\necho hello", expected: []string{"/bin/sh"}},
+		{name: "empty shebang", body: "#!\necho hello", expected: []string{"sh"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd, err := stdinInterpreter(tt.interpreter, tt.body)
+			if tt.err {
+				require.ErrorIs(t, err, errScriptStdinInterpreter)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, cmd)
+		})
+	}
+}
@@ -0,0 +1,330 @@
+package linux
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/spf13/cast"
+)
+
+const (
+	attrSymlinkProviderOverride   = "provider_override"
+	attrSymlinkPath               = "path"
+	attrSymlinkTarget             = "target"
+	attrSymlinkOwner              = "owner"
+	attrSymlinkGroup              = "group"
+	attrSymlinkOwnerName          = "owner_name"
+	attrSymlinkGroupName          = "group_name"
+	attrSymlinkOverwrite          = "overwrite"
+	attrSymlinkRecyclePath        = "recycle_path"
+	attrSymlinkRecycleRetention   = "recycle_retention"
+	attrSymlinkRecycleMaxItems    = "recycle_max_items"
+	attrSymlinkRestoreFromRecycle = "restore_from_recycle"
+	attrSymlinkDeleteStrategy     = "delete_strategy"
+)
+
+var schemaSymlinkResource = map[string]*schema.Schema{
+	attrSymlinkProviderOverride: {
+		Type:     schema.TypeList,
+		Optional: true,
+		MaxItems: 1,
+		Elem: &schema.Resource{
+			Schema: subSchemaProviderOverride,
+		},
+	},
+
+	attrSymlinkPath: {
+		Type:     schema.TypeString,
+		Required: true,
+	},
+	attrSymlinkTarget: {
+		Type:     schema.TypeString,
+		Required: true,
+	},
+	attrSymlinkOwner: {
+		Type:         schema.TypeInt,
+		Optional:     true,
+		Computed:     true,
+		ValidateFunc: validateID,
+	},
+	attrSymlinkOwnerName: {
+		Type:          schema.TypeString,
+		Optional:      true,
+		Computed:      true,
+		ConflictsWith: []string{attrSymlinkOwner},
+		Description:   "User name of the symlink, resolved on remote. Conflicts with `owner`",
+	},
+	attrSymlinkGroup: {
+		Type:         schema.TypeInt,
+		Optional:     true,
+		Computed:     true,
+		ValidateFunc: validateID,
+	},
+	attrSymlinkGroupName: {
+		Type:          schema.TypeString,
+		Optional:      true,
+		Computed:      true,
+		ConflictsWith: []string{attrSymlinkGroup},
+		Description:   "Group name of the symlink, resolved on remote. Conflicts with `group`",
+	},
+	attrSymlinkOverwrite: {
+		Type:        schema.TypeBool,
+		Optional:    true,
+		Default:     false,
+		Description: "If true, existing file or symlink on remote will be replaced on create or update",
+	},
+	attrSymlinkRecyclePath: {
+		Type:        schema.TypeString,
+		Optional:    true,
+		Default:     "",
+		Description: "Path to parent directory of a generated-unix-timestamp directory where the symlink will be placed on destroy",
+	},
+	attrSymlinkRecycleRetention: {
+		Type:         schema.TypeString,
+		Optional:     true,
+		Default:      "",
+		ValidateFunc: validateRetention,
+		Description:  "Duration, e.g. `720h`, after which generations inside `recycle_path` are removed whenever another item is recycled",
+	},
+	attrSymlinkRecycleMaxItems: {
+		Type:         schema.TypeInt,
+		Optional:     true,
+		Default:      0,
+		ValidateFunc: validation.IntAtLeast(0),
+		Description:  "Maximum number of generations kept inside `recycle_path`. The oldest ones are removed whenever another item is recycled",
+	},
+	attrSymlinkDeleteStrategy: {
+		Type:         schema.TypeString,
+		Optional:     true,
+		Default:      "",
+		ValidateFunc: validation.StringInSlice([]string{deleteStrategyRemove, deleteStrategyRecycle, deleteStrategyRetain}, false),
+		Description:  "What happens to the symlink on destroy, one of `remove`, `recycle` or `retain`. Default to `recycle` when `recycle_path` is set, otherwise `remove`",
+	},
+	attrSymlinkRestoreFromRecycle: {
+		Type:        schema.TypeBool,
+		Optional:    true,
+		Default:     false,
+		Description: "If true, the latest symlink recycled from the same path inside `recycle_path` will be restored on create",
+	},
+}
+
+type handlerSymlinkResource struct{}
+
+func (handlerSymlinkResource) newSymlink(rd *schema.ResourceData) (s *symlink) {
+	if rd == nil {
+		return
+	}
+	s = &symlink{
+		path:   cast.ToString(rd.Get(attrSymlinkPath)),
+		target: cast.ToString(rd.Get(attrSymlinkTarget)),
+		permission: permission{
+			owner: cast.ToUint32(rd.Get(attrSymlinkOwner)),
+			group: cast.ToUint32(rd.Get(attrSymlinkGroup)),
+
+			ownerName: cast.ToString(rd.Get(attrSymlinkOwnerName)),
+			groupName: cast.ToString(rd.Get(attrSymlinkGroupName)),
+		},
+		overwrite:      cast.ToBool(rd.Get(attrSymlinkOverwrite)),
+		recycle:        newRecycleBin(rd, attrSymlinkRecyclePath, attrSymlinkRecycleRetention, attrSymlinkRecycleMaxItems),
+		deleteStrategy: cast.ToString(rd.Get(attrSymlinkDeleteStrategy)),
+		restore:        cast.ToBool(rd.Get(attrSymlinkRestoreFromRecycle)),
+	}
+	s.permission = unmanagedPermission(rd.GetRawConfig(), s.permission,
+		attrSymlinkOwner, attrSymlinkOwnerName, attrSymlinkGroup, attrSymlinkGroupName, "")
+	return
+}
+
+func (handlerSymlinkResource) newDiffedSymlink(rd *schema.ResourceData) (old, new *symlink) {
+	if rd == nil {
+		return
+	}
+	old, new = &symlink{}, &symlink{}
+
+	o, n := rd.GetChange(attrSymlinkPath)
+	old.path, new.path = cast.ToString(o), cast.ToString(n)
+
+	o, n = rd.GetChange(attrSymlinkTarget)
+	old.target, new.target = cast.ToString(o), cast.ToString(n)
+
+	o, n = rd.GetChange(attrSymlinkOwner)
+	old.permission.owner, new.permission.owner = cast.ToUint32(o), cast.ToUint32(n)
+
+	o, n = rd.GetChange(attrSymlinkGroup)
+	old.permission.group, new.permission.group = cast.ToUint32(o), cast.ToUint32(n)
+
+	o, n = rd.GetChange(attrSymlinkOwnerName)
+	old.permission.ownerName, new.permission.ownerName = cast.ToString(o), cast.ToString(n)
+	if !configured(rd.GetRawConfig(), attrSymlinkOwnerName) {
+		old.permission.ownerName, new.permission.ownerName = "", "" // compare by id
+	}
+
+	o, n = rd.GetChange(attrSymlinkGroupName)
+	old.permission.groupName, new.permission.groupName = cast.ToString(o), cast.ToString(n)
+	if !configured(rd.GetRawConfig(), attrSymlinkGroupName) {
+		old.permission.groupName, new.permission.groupName = "", "" // compare by id
+	}
+
+	o, n = rd.GetChange(attrSymlinkOverwrite)
+	old.overwrite, new.overwrite = cast.ToBool(o), cast.ToBool(n)
+
+	o, n = rd.GetChange(attrSymlinkRecyclePath)
+	old.recycle.path, new.recycle.path = cast.ToString(o), cast.ToString(n)
+
+	o, n = rd.GetChange(attrSymlinkRecycleRetention)
+	old.recycle.retention, new.recycle.retention = cast.ToString(o), cast.ToString(n)
+
+	o, n = rd.GetChange(attrSymlinkRecycleMaxItems)
+	old.recycle.maxItems, new.recycle.maxItems = cast.ToInt(o), cast.ToInt(n)
+
+	o, n = rd.GetChange(attrSymlinkDeleteStrategy)
+	old.deleteStrategy, new.deleteStrategy = cast.ToString(o), cast.ToString(n)
+
+	o, n = rd.GetChange(attrSymlinkRestoreFromRecycle)
+	old.restore, new.restore = cast.ToBool(o), cast.ToBool(n)
+
+	old.permission = unmanagedPermission(rd.GetRawConfig(), old.permission,
+		attrSymlinkOwner, attrSymlinkOwnerName, attrSymlinkGroup, attrSymlinkGroupName, "")
+	new.permission = unmanagedPermission(rd.GetRawConfig(), new.permission,
+		attrSymlinkOwner, attrSymlinkOwnerName, attrSymlinkGroup, attrSymlinkGroupName, "")
+	return
+}
+
+func (handlerSymlinkResource) updateResourceData(s *symlink, rd *schema.ResourceData) (err error) {
+	if s == nil {
+		rd.SetId("")
+		return
+	}
+
+	if err = rd.Set(attrSymlinkPath, s.path); err != nil {
+		return
+	}
+	if err = rd.Set(attrSymlinkTarget, s.target); err != nil {
+		return
+	}
+	if s.permission.owner != idUnmanaged {
+		if err = rd.Set(attrSymlinkOwner, s.permission.owner); err != nil {
+			return
+		}
+	}
+	if s.permission.group != idUnmanaged {
+		if err = rd.Set(attrSymlinkGroup, s.permission.group); err != nil {
+			return
+		}
+	}
+	if err = rd.Set(attrSymlinkOwnerName, s.permission.ownerName); err != nil {
+		return
+	}
+	if err = rd.Set(attrSymlinkGroupName, s.permission.groupName); err != nil {
+		return
+	}
+	if err = rd.Set(attrSymlinkOverwrite, s.overwrite); err != nil {
+		return
+	}
+	if err = rd.Set(attrSymlinkRecyclePath, s.recycle.path); err != nil {
+		return
+	}
+	if err = rd.Set(attrSymlinkRecycleRetention, s.recycle.retention); err != nil {
+		return
+	}
+	if err = rd.Set(attrSymlinkRecycleMaxItems, s.recycle.maxItems); err != nil {
+		return
+	}
+	if err = rd.Set(attrSymlinkDeleteStrategy, s.deleteStrategy); err != nil {
+		return
+	}
+	if err = rd.Set(attrSymlinkRestoreFromRecycle, s.restore); err != nil {
+		return
+	}
+	return
+}
+
+func (h handlerSymlinkResource) Read(ctx context.Context, rd *schema.ResourceData, meta interface{}) (d diag.Diagnostics) {
+	l, err := getLinux(meta.(*linuxPool), rd)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	s, err := l.readSymlink(ctx, cast.ToString(rd.Get(attrSymlinkPath)))
+	if errors.Is(err, errPathNotExist) {
+		rd.SetId("")
+		return
+	}
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	s.overwrite = cast.ToBool(rd.Get(attrSymlinkOverwrite))
+	s.recycle = newRecycleBin(rd, attrSymlinkRecyclePath, attrSymlinkRecycleRetention, attrSymlinkRecycleMaxItems)
+	s.deleteStrategy = cast.ToString(rd.Get(attrSymlinkDeleteStrategy))
+	s.restore = cast.ToBool(rd.Get(attrSymlinkRestoreFromRecycle))
+	if err = h.updateResourceData(s, rd); err != nil {
+		return diag.FromErr(err)
+	}
+	return
+}
+
+func (h handlerSymlinkResource) Create(ctx context.Context, rd *schema.ResourceData, meta interface{}) (d diag.Diagnostics) {
+	l, err := getLinux(meta.(*linuxPool), rd)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	s := h.newSymlink(rd)
+	if err := l.createSymlink(ctx, s); err != nil {
+		return diag.FromErr(err)
+	}
+
+	id, err := uuid.NewRandom()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	rd.SetId(id.String())
+	return h.Read(ctx, rd, meta)
+}
+
+func (h handlerSymlinkResource) Update(ctx context.Context, rd *schema.ResourceData, meta interface{}) (d diag.Diagnostics) {
+	l, err := getLinux(meta.(*linuxPool), rd)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	old, new := h.newDiffedSymlink(rd)
+	err = l.updateSymlink(ctx, old, new)
+	if err != nil {
+		_ = h.updateResourceData(old, rd) // WARN: see https://github.com/hashicorp/terraform-plugin-sdk/issues/476
+		return diag.FromErr(err)
+	}
+
+	return h.Read(ctx, rd, meta)
+}
+
+func (h handlerSymlinkResource) Delete(ctx context.Context, rd *schema.ResourceData, meta interface{}) (d diag.Diagnostics) {
+	l, err := getLinux(meta.(*linuxPool), rd)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := l.deleteSymlink(ctx, h.newSymlink(rd)); err != nil {
+		return diag.FromErr(err)
+	}
+	return
+}
+
+func (h handlerSymlinkResource) CustomizeDiff(ctx context.Context, rd *schema.ResourceDiff, meta interface{}) (err error) {
+	return validateDeleteStrategy(rd, attrSymlinkDeleteStrategy, attrSymlinkRecyclePath)
+}
+
+func symlinkResource() *schema.Resource {
+	var hsr handlerSymlinkResource
+	return &schema.Resource{
+		Schema:        schemaSymlinkResource,
+		CreateContext: hsr.Create,
+		ReadContext:   hsr.Read,
+		UpdateContext: hsr.Update,
+		DeleteContext: hsr.Delete,
+		CustomizeDiff: hsr.CustomizeDiff,
+	}
+}
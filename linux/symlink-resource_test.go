@@ -0,0 +1,412 @@
+package linux
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccLinuxSymlinkBasic(t *testing.T) {
+	conf1 := tfConf{
+		Provider: testAccProvider,
+		Symlink:  tNewTFMapSymlink().Without(attrSymlinkOwner, attrSymlinkGroup),
+	}
+	conf2 := conf1.Copy(func(tc *tfConf) {
+		tc.Symlink.With(attrSymlinkTarget, fmt.Sprintf(`"/tmp/linux/%s"`, acctest.RandString(16)))
+	})
+	conf3 := tfConf{
+		Provider: testAccProvider,
+		Symlink:  tNewTFMapSymlink(),
+		Extra:    tfmap{"path_previous": conf1.Symlink[attrSymlinkPath]},
+	}
+
+	resource.Test(t, resource.TestCase{
+		ExternalProviders: map[string]resource.ExternalProvider{"null": {}},
+		PreCheck:          testAccPreCheckConnection(t),
+		Providers:         testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccLinuxSymlinkBasicConfig(t, conf1),
+			},
+			{
+				Config: testAccLinuxSymlinkBasicConfig(t, conf2),
+			},
+			{
+				Config: testAccLinuxSymlinkBasicConfig(t, conf3),
+			},
+		},
+	})
+}
+
+func testAccLinuxSymlinkBasicConfig(t *testing.T, conf tfConf) (s string) {
+	tf := heredoc.Doc(`
+		provider "linux" {
+			alias = "test"
+		    {{- .Provider.Serialize | nindent 4 }}
+		}
+
+		resource "null_resource" "destroy_validator" {
+		    connection {
+		        type = "ssh"
+		        {{- .Provider.Serialize | nindent 8 }}
+		    }
+		    provisioner "remote-exec" {
+		        when = destroy
+		        inline = [
+		            <<-EOF
+		            [ ! -L {{ .Symlink.path }} ] || exit 100
+		            EOF
+		        ]
+		    }
+		}
+
+		resource "linux_symlink" "symlink" {
+			provider = "linux.test"
+		    depends_on = [ null_resource.destroy_validator ]
+
+		    {{- .Symlink.Serialize | nindent 4 }}
+		}
+
+		resource "null_resource" "create_validator" {
+		    triggers = {
+		        {{- range $key, $value := .Symlink }}
+		            {{- $key | nindent 8 }} = linux_symlink.symlink.{{ $key }}
+		        {{- end}}
+
+		        path_previous = {{ .Extra.path_previous | default "0"}}
+		    }
+		    connection {
+		        type = "ssh"
+		        {{- .Provider.Serialize | nindent 8 }}
+		    }
+		    provisioner "remote-exec" {
+		        inline = [
+		            <<-EOF
+		                [ ! -L "${self.triggers["path_previous"]}"  ] || exit 101
+
+		                [ -L "${self.triggers["path"]}" ] || exit 102
+		                [ "$( readlink '${self.triggers["path"]}' )" == "${self.triggers["target"]}" ] || exit 103
+		                [ "$( stat -c %u '${self.triggers["path"]}' )" == "{{ .Symlink.owner | default 0 }}" ] || exit 104
+		                [ "$( stat -c %g '${self.triggers["path"]}' )" == "{{ .Symlink.group | default 0 }}" ] || exit 105
+		            EOF
+		        ]
+		    }
+		}
+	`)
+
+	s, err := conf.compile(tf)
+	t.Log(s)
+	require.NoError(t, err, "compile template failed")
+	return
+}
+
+func TestAccLinuxSymlinkOverwrite(t *testing.T) {
+	conf1 := tfConf{
+		Provider: testAccProvider,
+		Symlink:  tNewTFMapSymlink(),
+	}
+	conf2 := conf1.Copy(func(tc *tfConf) {
+		tc.Symlink.With(attrSymlinkOverwrite, "true")
+	})
+
+	resource.Test(t, resource.TestCase{
+		ExternalProviders: map[string]resource.ExternalProvider{"null": {}},
+		PreCheck:          testAccPreCheckConnection(t),
+		Providers:         testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccLinuxSymlinkOverwriteConfig(t, conf1),
+				ExpectError: regexp.MustCompile(" exist"),
+			},
+			{
+				Config: testAccLinuxSymlinkOverwriteConfig(t, conf2),
+			},
+		},
+	})
+}
+
+func testAccLinuxSymlinkOverwriteConfig(t *testing.T, conf tfConf) (s string) {
+	tf := heredoc.Doc(`
+		provider "linux" {
+			alias = "test"
+		    {{- .Provider.Serialize | nindent 4 }}
+		}
+
+		resource "null_resource" "existing_file" {
+		    triggers = {
+		        path = {{ .Symlink.path }}
+		    }
+		    connection {
+		        type = "ssh"
+		        {{- .Provider.Serialize | nindent 8 }}
+		    }
+		    provisioner "remote-exec" {
+		        inline = [ "mkdir -p ${ dirname(self.triggers["path"]) }" ]
+		    }
+		    provisioner "file" {
+		        content = "existing"
+		        destination = self.triggers["path"]
+		    }
+		}
+
+		resource "linux_symlink" "symlink" {
+			provider = "linux.test"
+		    depends_on = [ null_resource.existing_file ]
+
+		    {{- .Symlink.Serialize | nindent 4 }}
+		}
+
+		resource "null_resource" "create_validator" {
+		    triggers = {
+		        path = linux_symlink.symlink.path
+		        target = linux_symlink.symlink.target
+		    }
+		    connection {
+		        type = "ssh"
+		        {{- .Provider.Serialize | nindent 8 }}
+		    }
+		    provisioner "remote-exec" {
+		        inline = [
+		            <<-EOF
+		                [ "$( readlink '${self.triggers["path"]}' )" == "${self.triggers["target"]}" ] || exit 101
+		            EOF
+		        ]
+		    }
+		}
+	`)
+
+	s, err := conf.compile(tf)
+	t.Log(s)
+	require.NoError(t, err, "compile template failed")
+	return
+}
+
+func TestAccLinuxSymlinkPathOverwrite(t *testing.T) {
+	existing := fmt.Sprintf(`"/tmp/linux/%s"`, acctest.RandString(16))
+	conf1 := tfConf{
+		Provider: testAccProvider,
+		Symlink:  tNewTFMapSymlink().Without(attrSymlinkOwner, attrSymlinkGroup).With(attrSymlinkOverwrite, "true"),
+		Extra:    tfmap{"existing": existing, "kind": "file"},
+	}
+	conf2 := conf1.Copy(func(tc *tfConf) {
+		tc.Symlink.With(attrSymlinkPath, existing)
+		tc.Extra.With("path_previous", conf1.Symlink[attrSymlinkPath])
+	})
+
+	resource.Test(t, resource.TestCase{
+		ExternalProviders: map[string]resource.ExternalProvider{"null": {}},
+		PreCheck:          testAccPreCheckConnection(t),
+		Providers:         testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccLinuxSymlinkExistingConfig(t, conf1),
+			},
+			{
+				Config: testAccLinuxSymlinkExistingConfig(t, conf2),
+			},
+		},
+	})
+}
+
+func TestAccLinuxSymlinkDirectory(t *testing.T) {
+	conf := tfConf{
+		Provider: testAccProvider,
+		Symlink:  tNewTFMapSymlink().Without(attrSymlinkOwner, attrSymlinkGroup).With(attrSymlinkOverwrite, "true"),
+	}
+	conf.Extra = tfmap{"existing": conf.Symlink[attrSymlinkPath], "kind": "directory"}
+
+	resource.Test(t, resource.TestCase{
+		ExternalProviders: map[string]resource.ExternalProvider{"null": {}},
+		PreCheck:          testAccPreCheckConnection(t),
+		Providers:         testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccLinuxSymlinkExistingConfig(t, conf),
+				ExpectError: regexp.MustCompile("is a directory"),
+			},
+		},
+	})
+}
+
+func testAccLinuxSymlinkExistingConfig(t *testing.T, conf tfConf) (s string) {
+	tf := heredoc.Doc(`
+		provider "linux" {
+			alias = "test"
+		    {{- .Provider.Serialize | nindent 4 }}
+		}
+
+		resource "null_resource" "existing" {
+		    triggers = {
+		        path = {{ .Extra.existing }}
+		    }
+		    connection {
+		        type = "ssh"
+		        {{- .Provider.Serialize | nindent 8 }}
+		    }
+		    provisioner "remote-exec" {
+		        inline = [
+		            <<-EOF
+		                mkdir -p "$(dirname '${self.triggers["path"]}')"
+		                {{- if eq .Extra.kind "directory" }}
+		                mkdir -p '${self.triggers["path"]}'
+		                {{- else }}
+		                printf 'existing' > '${self.triggers["path"]}'
+		                {{- end }}
+		            EOF
+		        ]
+		    }
+		    provisioner "remote-exec" {
+		        when = destroy
+		        inline = [ "rm -rf '${self.triggers["path"]}'" ]
+		    }
+		}
+
+		resource "linux_symlink" "symlink" {
+			provider = "linux.test"
+		    depends_on = [ null_resource.existing ]
+
+		    {{- .Symlink.Serialize | nindent 4 }}
+		}
+
+		resource "null_resource" "create_validator" {
+		    triggers = {
+		        path = linux_symlink.symlink.path
+		        target = linux_symlink.symlink.target
+		        path_previous = {{ .Extra.path_previous | default "0" }}
+		    }
+		    connection {
+		        type = "ssh"
+		        {{- .Provider.Serialize | nindent 8 }}
+		    }
+		    provisioner "remote-exec" {
+		        inline = [
+		            <<-EOF
+		                [ ! -L "${self.triggers["path_previous"]}" ] || exit 101
+		                [ "$( readlink '${self.triggers["path"]}' )" == "${self.triggers["target"]}" ] || exit 102
+		            EOF
+		        ]
+		    }
+		}
+	`)
+
+	s, err := conf.compile(tf)
+	t.Log(s)
+	require.NoError(t, err, "compile template failed")
+	return
+}
+
+func TestAccLinuxSymlinkRecycle(t *testing.T) {
+	conf1 := tfConf{
+		Provider: testAccProvider,
+		Symlink: tNewTFMapSymlink().
+			With(attrSymlinkRecyclePath, fmt.Sprintf(`"/tmp/recycle/%s"`, acctest.RandString(16))),
+		Extra: tfmap{"symlink": "true"},
+	}
+	conf2 := conf1.Copy(func(tc *tfConf) {
+		tc.Extra.With("symlink", "false")
+	})
+	conf3 := conf1.Copy(func(tc *tfConf) {
+		tc.Symlink.With(attrSymlinkRestoreFromRecycle, "true")
+		tc.Extra.With("restored", "true")
+	})
+	conf4 := conf3.Copy(func(tc *tfConf) {
+		tc.Symlink.With(attrSymlinkDeleteStrategy, `"retain"`)
+	})
+	conf5 := conf4.Copy(func(tc *tfConf) {
+		tc.Extra.With("symlink", "false").With("retained", "true")
+	})
+
+	resource.Test(t, resource.TestCase{
+		ExternalProviders: map[string]resource.ExternalProvider{"null": {}},
+		PreCheck:          testAccPreCheckConnection(t),
+		Providers:         testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccLinuxSymlinkRecycleConfig(t, conf1),
+			},
+			{
+				Config: testAccLinuxSymlinkRecycleConfig(t, conf2),
+			},
+			{
+				Config: testAccLinuxSymlinkRecycleConfig(t, conf3),
+			},
+			{
+				Config: testAccLinuxSymlinkRecycleConfig(t, conf4),
+			},
+			{
+				Config: testAccLinuxSymlinkRecycleConfig(t, conf5),
+			},
+		},
+	})
+}
+
+func testAccLinuxSymlinkRecycleConfig(t *testing.T, conf tfConf) (s string) {
+	tf := heredoc.Doc(`
+		provider "linux" {
+			alias = "test"
+		    {{- .Provider.Serialize | nindent 4 }}
+		}
+
+		resource "null_resource" "destroy_checker" {
+		    triggers = {
+		        path = {{ .Symlink.path }}
+		        recycle_path = {{ .Symlink.recycle_path }}
+		    }
+		    connection {
+		        type = "ssh"
+		        {{- .Provider.Serialize | nindent 8 }}
+		    }
+		    provisioner "remote-exec" {
+		        when = destroy
+		        inline = [ "rm -rf '${self.triggers["path"]}' '${self.triggers["recycle_path"]}'" ]
+		    }
+		}
+
+		{{- if eq .Extra.symlink "true" }}
+
+		resource "linux_symlink" "symlink" {
+			provider = "linux.test"
+		    depends_on = [ null_resource.destroy_checker ]
+
+		    {{- .Symlink.Serialize | nindent 4 }}
+		}
+		{{- end }}
+
+		resource "null_resource" "validator" {
+		    triggers = {
+		        symlink = {{ .Extra.symlink | quote }}
+		        restored = {{ .Extra.restored | default "false" | quote }}
+		        retained = {{ .Extra.retained | default "false" | quote }}
+		    }
+		    depends_on = [ null_resource.destroy_checker {{- if eq .Extra.symlink "true" }}, linux_symlink.symlink {{- end }} ]
+		    connection {
+		        type = "ssh"
+		        {{- .Provider.Serialize | nindent 8 }}
+		    }
+		    provisioner "remote-exec" {
+		        inline = [
+		            <<-EOF
+		                IN_BIN="$(find {{ .Symlink.recycle_path }} -mindepth 2 -name "$(basename {{ .Symlink.path }})" 2>/dev/null)"
+		                if [ "${self.triggers["symlink"]}" = "true" ] || [ "${self.triggers["retained"]}" = "true" ]; then
+		                    [ "$(readlink {{ .Symlink.path }})" = {{ .Symlink.target }} ] || exit 101
+		                    [ "${self.triggers["restored"]}" = "false" ] || [ -z "$IN_BIN" ] || exit 102
+		                else
+		                    [ ! -L {{ .Symlink.path }} ] || exit 103
+		                    [ -n "$IN_BIN" ] || exit 104
+		                fi
+		            EOF
+		        ]
+		    }
+		}
+	`)
+
+	s, err := conf.compile(tf)
+	t.Log(s)
+	require.NoError(t, err, "compile template failed")
+	return
+}
@@ -0,0 +1,115 @@
+package linux
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"al.essio.dev/pkg/shellescape"
+	"github.com/hashicorp/terraform/communicator/remote"
+)
+
+type symlink struct {
+	path       string
+	target     string
+	permission permission
+
+	overwrite      bool
+	recycle        recycleBin
+	restore        bool
+	deleteStrategy string
+}
+
+// readlink returns the target of the symlink at path, or empty string when path is not a symlink.
+func (l *linux) readlink(ctx context.Context, path string) (target string, err error) {
+	stdout := new(strings.Builder)
+	cmd := fmt.Sprintf(`{ [ ! -L %s ] || %s ;}`, shellescape.Quote(path), shellescape.QuoteCommand([]string{"readlink", path}))
+	if err = l.exec(ctx, &remote.Cmd{Command: cmd, Stdout: stdout}); err != nil {
+		return
+	}
+	return strings.TrimSuffix(stdout.String(), "\n"), nil
+}
+
+func (l *linux) readSymlink(ctx context.Context, path string) (s *symlink, err error) {
+	perm, err := l.getPermission(ctx, path)
+	if err != nil {
+		return
+	}
+	target, err := l.readlink(ctx, path)
+	if err != nil {
+		return
+	}
+	s = &symlink{path: path, target: target, permission: perm}
+	return
+}
+
+func (l *linux) createSymlink(ctx context.Context, s *symlink) (err error) {
+	if s == nil {
+		return errNil
+	}
+	if s.restore {
+		restored, err := l.restoreFromRecycleBin(ctx, s.path, s.recycle)
+		if err != nil {
+			return err
+		}
+		s.overwrite = s.overwrite || restored // the restored path is expected to exist
+	}
+
+	if !s.overwrite {
+		if err = l.reservePath(ctx, s.path); err != nil {
+			return
+		}
+	}
+
+	err = l.mkdirp(ctx, filepath.Dir(s.path))
+	if err != nil {
+		return
+	}
+
+	perm, err := l.resolvePermission(ctx, s.permission)
+	if err != nil {
+		return
+	}
+	pathSafe := shellescape.Quote(s.path)
+	cmd := fmt.Sprintf(`{ [ ! -d %s ] || [ -L %s ] || { echo %s >&2 && exit 1 ;} ;} && %s`,
+		pathSafe, pathSafe, shellescape.Quote(fmt.Sprintf("path '%s' is a directory", s.path)),
+		shellescape.QuoteCommand([]string{"ln", "-sfn", s.target, s.path}),
+	)
+	if spec := perm.chownSpec(); spec != "" {
+		cmd = fmt.Sprintf(`%s && %s`, cmd, shellescape.QuoteCommand([]string{"chown", "-h", spec, s.path}))
+	}
+	return l.exec(ctx, &remote.Cmd{Command: cmd})
+}
+
+func (l *linux) deleteSymlink(ctx context.Context, s *symlink) (err error) {
+	if s == nil {
+		return
+	}
+	return l.remove(ctx, s.path, newDeleteStrategy(s.deleteStrategy, s.recycle))
+}
+
+func (l *linux) updateSymlink(ctx context.Context, old, new *symlink) (err error) {
+	if old == nil {
+		return l.createSymlink(ctx, new)
+	}
+	if new == nil {
+		return l.deleteSymlink(ctx, old)
+	}
+
+	if old.path != new.path {
+		if !new.overwrite {
+			if err = l.reservePath(ctx, new.path); err != nil {
+				return
+			}
+		}
+		if err = l.remove(ctx, old.path, deleteRemove{}); err != nil {
+			return
+		}
+	}
+
+	s := &symlink{}
+	*s = *new
+	s.overwrite = true
+	return l.createSymlink(ctx, s)
+}
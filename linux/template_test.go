@@ -2,6 +2,7 @@ package linux
 
 import (
 	"fmt"
+	"strings"
 	"text/template"
 
 	"github.com/MakeNowJust/heredoc"
@@ -81,6 +82,15 @@ func tNewTFMapFile() tfmap {
 	return m
 }
 
+func tNewTFMapSymlink() tfmap {
+	m := tfmap{}
+	m[attrSymlinkPath] = fmt.Sprintf(`"/tmp/linux/%s"`, acctest.RandString(16))
+	m[attrSymlinkTarget] = fmt.Sprintf(`"/tmp/linux/%s"`, acctest.RandString(16))
+	m[attrSymlinkOwner] = fmt.Sprintf("%d", acctest.RandInt()%1000+1000)
+	m[attrSymlinkGroup] = fmt.Sprintf("%d", acctest.RandInt()%1000+1000)
+	return m
+}
+
 type tfList []string
 
 func (l tfList) Copy() (c tfList) {
@@ -109,7 +119,11 @@ type tfScript struct {
 	SensitiveEnvironment tfmap
 	WorkingDirectory     string
 	Interpreter          tfList
+	ExecutionMode        string
+	OutputFormat         string
+	WarnOnStderr         string
 	LifecycleCommands    tfmap
+	LifecycleSettings    map[string]tfmap
 }
 
 func (t tfScript) Copy(modifers ...func(*tfScript)) (c tfScript) {
@@ -118,7 +132,16 @@ func (t tfScript) Copy(modifers ...func(*tfScript)) (c tfScript) {
 	c.SensitiveEnvironment = t.SensitiveEnvironment.Copy()
 	c.WorkingDirectory = t.WorkingDirectory
 	c.Interpreter = t.Interpreter.Copy()
+	c.ExecutionMode = t.ExecutionMode
+	c.OutputFormat = t.OutputFormat
+	c.WarnOnStderr = t.WarnOnStderr
 	c.LifecycleCommands = t.LifecycleCommands.Copy()
+	if t.LifecycleSettings != nil {
+		c.LifecycleSettings = map[string]tfmap{}
+		for k, v := range t.LifecycleSettings {
+			c.LifecycleSettings[k] = v.Copy()
+		}
+	}
 
 	for _, m := range modifers {
 		m(&c)
@@ -137,7 +160,11 @@ func (t tfScript) Serialize() (s string, err error) {
 			"SensitiveEnvironment": attrScriptSensitiveEnvironment,
 			"WorkingDirectory":     attrScriptWorkingDirectory,
 			"Interpreter":          attrScriptInterpreter,
+			"ExecutionMode":        attrScriptExecutionMode,
+			"OutputFormat":         attrScriptOutputFormat,
+			"WarnOnStderr":         attrScriptWarnOnStderr,
 			"LifecycleCommands":    attrScriptLifecycleCommands,
+			"LifecycleSettings":    attrScriptLifecycleSettings,
 		},
 		Value: t,
 	}
@@ -145,6 +172,15 @@ func (t tfScript) Serialize() (s string, err error) {
 		{{ if .Value.LifecycleCommands -}}
 		    {{- .Key.LifecycleCommands | nindent 0 }} {
 		        {{- .Value.LifecycleCommands.Serialize | nindent 4 }}
+		        {{- if .Value.LifecycleSettings }}
+		            {{- print .Key.LifecycleSettings " {" | nindent 4 }}
+		            {{- range $lc, $settings := .Value.LifecycleSettings }}
+		                {{- print $lc " {" | nindent 8 }}
+		                    {{- $settings.Serialize | nindent 12 }}
+		                {{- "}" | nindent 8 }}
+		            {{- end }}
+		            {{- "}" | nindent 4 }}
+		        {{- end }}
 		    {{- "}" | nindent 0 -}}
 		{{ end -}}
 
@@ -170,6 +206,18 @@ func (t tfScript) Serialize() (s string, err error) {
 		    {{- .Key.WorkingDirectory | nindent 0 }} = {{ .Value.WorkingDirectory }}
 		{{ end }}
 
+		{{ if .Value.ExecutionMode }}
+		    {{- .Key.ExecutionMode | nindent 0 }} = {{ .Value.ExecutionMode }}
+		{{ end }}
+
+		{{ if .Value.OutputFormat }}
+		    {{- .Key.OutputFormat | nindent 0 }} = {{ .Value.OutputFormat }}
+		{{ end }}
+
+		{{ if .Value.WarnOnStderr }}
+		    {{- .Key.WarnOnStderr | nindent 0 }} = {{ .Value.WarnOnStderr }}
+		{{ end }}
+
 		{{ if .Value.Interpreter -}}
 		    {{- .Key.Interpreter }} = [
 		        {{- .Value.Interpreter.Serialize | nindent 4 }}
@@ -184,12 +232,76 @@ type tfConf struct {
 	ProviderOverride tfmap
 	File             tfmap
 	Directory        tfmap
+	Symlink          tfmap
+	FileLine         tfmap
+	ConfigValue      tfmap
 	Script           tfScript
 	DataScript       tfScript
 	LocalForward     tfmap
 	Extra            tfmap
 }
 
+// tHookConfig returns the template of resource, e.g. `linux_file.file`, whose hooks append to the marker file at
+// `.Extra.marker`. It is surrounded by null_resources validating that the marker file holds `.Extra.expected` after
+// each apply and "create change destroy" after destroy. body is the template of the resource arguments and triggers
+// are the attributes whose changes rerun the validation.
+func tHookConfig(resource, body string, triggers ...string) string {
+	typ, name, _ := strings.Cut(resource, ".")
+	lines := make([]string, 0, len(triggers))
+	for _, attr := range triggers {
+		lines = append(lines, fmt.Sprintf("%s = %s.%s", attr, resource, attr))
+	}
+	return fmt.Sprintf(heredoc.Doc(`
+		provider "linux" {
+			alias = "test"
+		    {{- .Provider.Serialize | nindent 4 }}
+		}
+
+		resource "null_resource" "destroy_validator" {
+		    connection {
+		        type = "ssh"
+		        {{- .Provider.Serialize | nindent 8 }}
+		    }
+		    provisioner "remote-exec" {
+		        when = destroy
+		        inline = [
+		            <<-EOF
+		                [ "$(cat {{ .Extra.marker }} | xargs)" == "create change destroy" ] || exit 100
+		                rm -f {{ .Extra.marker }}
+		            EOF
+		        ]
+		    }
+		}
+
+		resource "%s" "%s" {
+			provider = linux.test
+		    depends_on = [ null_resource.destroy_validator ]
+
+		    %s
+		    hook_environment = {
+		        MARKER = {{ .Extra.marker }}
+		    }
+		}
+
+		resource "null_resource" "create_validator" {
+		    triggers = {
+		        %s
+		    }
+		    connection {
+		        type = "ssh"
+		        {{- .Provider.Serialize | nindent 8 }}
+		    }
+		    provisioner "remote-exec" {
+		        inline = [
+		            <<-EOF
+		                [ "$(cat {{ .Extra.marker }} | xargs)" == {{ .Extra.expected }} ] || exit 101
+		            EOF
+		        ]
+		    }
+		}
+	`), typ, name, body, strings.Join(lines, "\n        "))
+}
+
 func (c tfConf) compile(tmpl string) (string, error) {
 	return tCompileTemplate(tmpl, c)
 }
@@ -199,6 +311,9 @@ func (c tfConf) Copy(modifers ...func(*tfConf)) (n tfConf) {
 	n.ProviderOverride = c.ProviderOverride.Copy()
 	n.File = c.File.Copy()
 	n.Directory = c.Directory.Copy()
+	n.Symlink = c.Symlink.Copy()
+	n.FileLine = c.FileLine.Copy()
+	n.ConfigValue = c.ConfigValue.Copy()
 	n.Script = c.Script.Copy()
 	n.DataScript = c.DataScript.Copy()
 	n.Extra = c.Extra.Copy()